@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxml173"
+	"github.com/mdhender/wxconv/models/wxml200"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"log/slog"
+)
+
+// register the codecs for the versions that we know about.
+func init() {
+	mustRegister(&Codec{
+		Version:     "1.73",
+		Description: "Worldographer 2022",
+		Unmarshal: func(d *xml.Decoder, start xml.StartElement) (WXML, error) {
+			srcMap := &wxml173.Map{}
+			// convert from xml to a structure that's built just for the conversion
+			if err := d.DecodeElement(srcMap, &start); err != nil {
+				return nil, err
+			}
+			return srcMap, nil
+		},
+		Translate: func(wxml WXML, logger *slog.Logger) (*wxx.Map, error) {
+			m, ok := wxml.(*wxml173.Map)
+			if !ok {
+				return nil, fmt.Errorf("%T: %w", wxml, ErrUnsupportedWXMLVersion)
+			}
+			return wxmlV173ToWXX(m, logger)
+		},
+		Encode: func(w io.Writer, m *wxx.Map, version string) error {
+			t, err := WMAPToTMAPv173(m)
+			if err != nil {
+				return err
+			}
+			return t.EncodeTo(w)
+		},
+	})
+
+	// v2.x files are read on a best-effort basis; see the wxml200 package.
+	mustRegister(&Codec{
+		Version:     wxml200.Version,
+		Description: "Worldographer 2025",
+		Accepts:     wxml200.IsVersion,
+		Unmarshal: func(d *xml.Decoder, start xml.StartElement) (WXML, error) {
+			srcMap := &wxml200.Map{}
+			// convert from xml to a structure that's built just for the conversion
+			if err := d.DecodeElement(srcMap, &start); err != nil {
+				return nil, err
+			}
+			return srcMap, nil
+		},
+		Translate: func(wxml WXML, logger *slog.Logger) (*wxx.Map, error) {
+			m, ok := wxml.(*wxml200.Map)
+			if !ok {
+				return nil, fmt.Errorf("%T: %w", wxml, ErrUnsupportedWXMLVersion)
+			}
+			return wxmlV200ToWXX(m, logger)
+		},
+		// no encoder: we have no file saved by Worldographer 2025 to check
+		// that the v1.73 layout is one that it opens, so we don't write v2.x.
+	})
+}
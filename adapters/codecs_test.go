@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetVersion(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "maps", "basic.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wxml, err := UTF8ToWXML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		source, target, want string
+	}{
+		{source: "1.73", target: "1.73", want: "1.73"},
+		{source: "2.05", target: "1.73", want: "1.73"},
+	} {
+		m.Version = tc.source
+		var out bytes.Buffer
+		if err := WXXToUTF8(&out, m, tc.target); err != nil {
+			t.Errorf("%s to %s: %v", tc.source, tc.target, err)
+			continue
+		}
+		wxml, err := UTF8ToWXML(out.Bytes())
+		if err != nil {
+			t.Errorf("%s to %s: %v", tc.source, tc.target, err)
+		} else if got := wxml.BaseVersion(); got != tc.want {
+			t.Errorf("%s to %s: got version %q, want %q", tc.source, tc.target, got, tc.want)
+		}
+	}
+
+	// v2.x files are read, but not written
+	for _, version := range []string{"2.00", "2.05", "2.10"} {
+		if err := WXXToUTF8(&bytes.Buffer{}, m, version); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("%q: got %v, want %v", version, err, ErrUnsupportedVersion)
+		}
+		if _, err := LookupDecoder(version); err != nil {
+			t.Errorf("%q: decoder: %v", version, err)
+		}
+	}
+
+	for _, version := range []string{"2", "2.", "2.x", "2.1a", "20.0", "3.00", "1.72"} {
+		if err := WXXToUTF8(&bytes.Buffer{}, m, version); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("%q: got %v, want %v", version, err, ErrUnsupportedVersion)
+		}
+		if _, err := LookupDecoder(version); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("%q: decoder: got %v, want %v", version, err, ErrUnsupportedVersion)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.73", "1.73", 0},
+		{"1.73", "2.00", -1},
+		{"2.0", "10.0", -1},
+		{"10.0", "2.0", 1},
+		{"1.7", "1.73", -1},
+		{"1.73", "1.73.1", -1},
+		{"2.00", "2.0", 0},
+		{"2.00", "2.beta", -1},
+		{"2.alpha", "2.beta", -1},
+	} {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q): got %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterOrder(t *testing.T) {
+	saved := registry.codecs
+	defer func() {
+		registry.codecs = saved
+	}()
+	registry.codecs = nil
+	for _, version := range []string{"10.0", "2.0", "1.73"} {
+		if err := Register(&Codec{Version: version}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	for _, c := range Codecs() {
+		got = append(got, c.Version)
+	}
+	if want := "1.73 2.0 10.0"; strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -11,11 +11,13 @@ func (e Error) Error() string {
 }
 
 const (
+	ErrInvalidMetaData          = Error("invalid meta-data")
 	ErrMissingBOM               = Error("missing bom")
 	ErrMissingFinalByte         = Error("missing final byte")
 	ErrMissingXMLHeader         = Error("missing xml header")
 	ErrNotBigEndianUTF16Encoded = Error("not big-endian utf-16 encoded")
 	ErrNotImplemented           = Error("not implemented")
+	ErrUnsupportedMetaData      = Error("unsupported meta-data version")
 	ErrUnsupportedVersion       = Error("unsupported version")
 	ErrUnsupportedWXMLVersion   = Error("unsupported wxml version")
 )
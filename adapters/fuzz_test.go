@@ -0,0 +1,99 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// The fuzz targets feed hostile input to each decoding stage. None of them
+// may panic; errors are expected. Crashers are kept in testdata/fuzz so that
+// they run as part of the regular tests.
+
+func FuzzDecodeTile(f *testing.F) {
+	f.Add("1\t0\t0\t0\t0\tZ")
+	f.Add("3\t2500.5\t0\t0\t0\tZ\t0.2,0.4,0.6,1.0")
+	f.Add("2\t0\t1\t0\t5\t1\t2\t3\t4\t5\t6")
+	f.Add("1\t-20\t0\t0\t0\t7\t0\t0\t0\t0\t0\t0.5,0.5,0.5,1.0")
+	f.Add("1\t0\t0\t0\t101\tZ")
+	f.Fuzz(func(t *testing.T, line string) {
+		tile, err := decodeTile(line)
+		if err == nil && tile == nil {
+			t.Fatalf("%q: no tile and no error", line)
+		}
+	})
+}
+
+func FuzzDecodeTerrainMap(f *testing.F) {
+	f.Add("Blank\t0\tWater Sea\t1\tFlat Grazing Land\t2")
+	f.Add("")
+	f.Add("Blank\t0\tWater Sea")
+	f.Add("Blank\tzero")
+	f.Fuzz(func(t *testing.T, s string) {
+		list, err := decodeTerrainMap(s)
+		if err == nil && len(list) == 0 {
+			t.Fatalf("%q: no terrain and no error", s)
+		}
+	})
+}
+
+func FuzzDecodeRgba(f *testing.F) {
+	f.Add("0.2,0.4,0.6,1.0")
+	f.Add("null")
+	f.Add("")
+	f.Add("0.0,0.0,0.0,1.0")
+	f.Add("1,2,3")
+	f.Add("NaN,Inf,-Inf,1e400")
+	f.Fuzz(func(t *testing.T, s string) {
+		_, _ = decodeRgba(s)
+		_, _ = decodeZeroableRgba(s)
+	})
+}
+
+func FuzzUTF16ToUTF8(f *testing.F) {
+	f.Add([]byte{0xfe, 0xff, 0x00, 0x41})
+	f.Add([]byte{0xff, 0xfe, 0x41, 0x00})
+	f.Add([]byte{0xfe, 0xff, 0xd8, 0x3d, 0xde, 0x00}) // surrogate pair
+	f.Add([]byte{0xfe, 0xff, 0xd8, 0x3d})             // lone surrogate
+	f.Add([]byte{0xfe, 0xff, 0x00})                   // odd length
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got, err := UTF16ToUTF8(data)
+		streamed, streamErr := io.ReadAll(NewUTF16ToUTF8Reader(bytes.NewReader(data)))
+		if err == nil && streamErr == nil && !bytes.Equal(got, streamed) {
+			t.Fatalf("% x: UTF16ToUTF8 and NewUTF16ToUTF8Reader disagree: %q != %q", data, got, streamed)
+		}
+	})
+}
+
+func FuzzGZipToUTF16(f *testing.F) {
+	for _, s := range []string{"", "\xfe\xff\x00<"} {
+		data, err := UTF16ToGZip([]byte(s))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{0x1f, 0x8b})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = GZipToUTF16(data)
+	})
+}
+
+func FuzzUTF8ToWXX(f *testing.F) {
+	f.Add([]byte(xmlHeader + `<map type="WORLD" version="1.73"><terrainmap>Blank	0</terrainmap><tiles viewLevel="WORLD" tilesWide="1" tilesHigh="1"><tilerow>
+0	0	0	0	0	Z
+</tilerow></tiles></map>`))
+	f.Add([]byte(xmlHeader + `<map version="2.00"><tiles tilesWide="1" tilesHigh="2"><tilerow>0	0	0	0	0	Z</tilerow></tiles></map>`))
+	f.Add([]byte(xmlHeader + `<map version="1.73"><tiles tilesWide="1" tilesHigh="-1"><tilerow></tilerow></tiles></map>`))
+	f.Add([]byte(xmlHeader + `<map version="9.99"/>`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		wxml, err := UTF8ToWXML(data)
+		if err != nil {
+			return
+		}
+		_, _ = WXMLToWXX(wxml, nil)
+	})
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONToWXX converts JSON data (as created by exporting a map to JSON)
+// back into a WXX map.
+//
+// The meta-data block is validated first. If the meta-data version is newer
+// than wxx.MetaDataVersion, ErrUnsupportedMetaData is returned since we
+// can't know what the newer layout means.
+//
+// Unknown fields are rejected so that typos in hand-edited files are
+// reported rather than silently ignored. So is anything after the map,
+// such as a second document.
+func JSONToWXX(data []byte) (*wxx.Map, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	w := &wxx.Map{}
+	if err := dec.Decode(w); err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return nil, fmt.Errorf("json: unexpected data after the map")
+	}
+
+	if err := validateMetaData(w); err != nil {
+		return nil, err
+	}
+	if err := validateJSONTerrainMap(w); err != nil {
+		return nil, err
+	}
+	if err := validateJSONTiles(w); err != nil {
+		return nil, err
+	}
+	if err := validateJSONRgba(w); err != nil {
+		return nil, err
+	}
+	if err := validateJSONConfiguration(w); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// validateMetaData verifies that the meta-data block was written by a
+// version of this application that we understand.
+func validateMetaData(w *wxx.Map) error {
+	if w.MetaData.Version == "" {
+		return fmt.Errorf("meta-data.version: %w: missing version", ErrInvalidMetaData)
+	}
+	version, err := parseMetaDataVersion(w.MetaData.Version)
+	if err != nil {
+		return fmt.Errorf("meta-data.version: %w: %v", ErrInvalidMetaData, err)
+	}
+	current, err := parseMetaDataVersion(wxx.MetaDataVersion)
+	if err != nil {
+		panic(fmt.Sprintf("assert(wxx.MetaDataVersion is valid): %v", err))
+	}
+	for i := range version {
+		if version[i] < current[i] {
+			break
+		} else if version[i] > current[i] {
+			return fmt.Errorf("meta-data.version: %w: file is %q, this tool supports %q or earlier", ErrUnsupportedMetaData, w.MetaData.Version, wxx.MetaDataVersion)
+		}
+	}
+	if w.MetaData.Created != "" {
+		if _, err := time.Parse(time.RFC3339, w.MetaData.Created); err != nil {
+			return fmt.Errorf("meta-data.created: %w: %v", ErrInvalidMetaData, err)
+		}
+	}
+	if w.MetaData.Source.Created != "" {
+		if _, err := time.Parse(time.RFC3339, w.MetaData.Source.Created); err != nil {
+			return fmt.Errorf("meta-data.source.created: %w: %v", ErrInvalidMetaData, err)
+		}
+	}
+	return nil
+}
+
+// parseMetaDataVersion splits a "major.minor.patch" version into numbers.
+func parseMetaDataVersion(s string) (version [3]int, err error) {
+	fields := strings.Split(s, ".")
+	if len(fields) != 3 {
+		return version, fmt.Errorf("expected major.minor.patch, got %q", s)
+	}
+	for i, field := range fields {
+		if version[i], err = strconv.Atoi(field); err != nil {
+			return version, fmt.Errorf("expected major.minor.patch, got %q", s)
+		} else if version[i] < 0 {
+			return version, fmt.Errorf("expected major.minor.patch, got %q", s)
+		}
+	}
+	return version, nil
+}
+
+// validateJSONTerrainMap makes sure that the terrain list and the terrain
+// lookup agree. Hand-edited files may only have one of them populated, so
+// the missing one is rebuilt from the other.
+func validateJSONTerrainMap(w *wxx.Map) error {
+	if len(w.TerrainMap.List) == 0 && len(w.TerrainMap.Data) != 0 {
+		for label, index := range w.TerrainMap.Data {
+			w.TerrainMap.List = append(w.TerrainMap.List, &wxx.Terrain{Index: index, Label: label})
+		}
+		sort.Slice(w.TerrainMap.List, func(i, j int) bool {
+			return w.TerrainMap.List[i].Index < w.TerrainMap.List[j].Index
+		})
+	}
+
+	data := map[string]int{}
+	indexes := map[int]string{}
+	for i, t := range w.TerrainMap.List {
+		if t == nil {
+			return fmt.Errorf("terrainMap.list[%d]: missing terrain", i)
+		} else if t.Label == "" {
+			return fmt.Errorf("terrainMap.list[%d]: missing label", i)
+		} else if _, ok := data[t.Label]; ok {
+			return fmt.Errorf("terrainMap.list[%d]: %q: duplicate label", i, t.Label)
+		} else if label, ok := indexes[t.Index]; ok {
+			return fmt.Errorf("terrainMap.list[%d]: %q: index %d already used by %q", i, t.Label, t.Index, label)
+		}
+		data[t.Label], indexes[t.Index] = t.Index, t.Label
+	}
+	for label, index := range w.TerrainMap.Data {
+		if n, ok := data[label]; !ok {
+			return fmt.Errorf("terrainMap.data: %q: not in list", label)
+		} else if n != index {
+			return fmt.Errorf("terrainMap.data: %q: index %d does not match list index %d", label, index, n)
+		}
+	}
+	w.TerrainMap.Data = data
+
+	return nil
+}
+
+// validateJSONTiles verifies that the tile rows match the dimensions of the map
+// and that every tile refers to a known terrain.
+func validateJSONTiles(w *wxx.Map) error {
+	if w.Tiles.TilesWide < 0 || w.Tiles.TilesHigh < 0 {
+		return fmt.Errorf("tiles: invalid dimensions %d x %d", w.Tiles.TilesWide, w.Tiles.TilesHigh)
+	} else if len(w.Tiles.TileRows) != w.Tiles.TilesWide {
+		return fmt.Errorf("tiles: tilesWide is %d, found %d tile rows", w.Tiles.TilesWide, len(w.Tiles.TileRows))
+	}
+	terrains := map[int]bool{}
+	for _, t := range w.TerrainMap.List {
+		terrains[t.Index] = true
+	}
+	for x, tileRow := range w.Tiles.TileRows {
+		if len(tileRow) != w.Tiles.TilesHigh {
+			return fmt.Errorf("tiles: tilerow[%d]: tilesHigh is %d, found %d tiles", x, w.Tiles.TilesHigh, len(tileRow))
+		}
+		for y, tile := range tileRow {
+			if tile == nil {
+				return fmt.Errorf("tiles: tilerow[%d][%d]: missing tile", x, y)
+			} else if len(terrains) != 0 && !terrains[tile.Terrain] {
+				return fmt.Errorf("tiles: tilerow[%d][%d]: terrain %d: not in terrain map", x, y, tile.Terrain)
+			}
+			for _, resource := range []struct {
+				name  string
+				value int
+			}{
+				{"animal", tile.Resources.Animal},
+				{"brick", tile.Resources.Brick},
+				{"crops", tile.Resources.Crops},
+				{"gems", tile.Resources.Gems},
+				{"lumber", tile.Resources.Lumber},
+				{"metals", tile.Resources.Metals},
+				{"rock", tile.Resources.Rock},
+			} {
+				if resource.value < 0 || resource.value > 100 {
+					return fmt.Errorf("tiles: tilerow[%d][%d]: %s: invalid value %d", x, y, resource.name, resource.value)
+				}
+			}
+			if err := validateJSONColor(tile.CustomBackgroundColor); err != nil {
+				return fmt.Errorf("tiles: tilerow[%d][%d]: customBackgroundColor: %w", x, y, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateJSONRgba verifies all the colors outside the tiles and configuration.
+func validateJSONRgba(w *wxx.Map) error {
+	for _, c := range []struct {
+		path string
+		rgba *wxx.RGBA
+	}{
+		{"mapKey.backgroundcolor", w.MapKey.BackgroundColor},
+		{"mapKey.titleFontColor", w.MapKey.TitleFontColor},
+		{"mapKey.scaleFontColor", w.MapKey.ScaleFontColor},
+		{"mapKey.entryFontColor", w.MapKey.EntryFontColor},
+	} {
+		if err := validateJSONColor(c.rgba); err != nil {
+			return fmt.Errorf("%s: %w", c.path, err)
+		}
+	}
+	for i, f := range w.Features {
+		if f == nil {
+			return fmt.Errorf("features[%d]: missing feature", i)
+		} else if err := validateJSONColor(f.Color); err != nil {
+			return fmt.Errorf("features[%d].color: %w", i, err)
+		} else if err = validateJSONColor(f.RingColor); err != nil {
+			return fmt.Errorf("features[%d].ringcolor: %w", i, err)
+		} else if err = validateJSONLabelColors(f.Label); err != nil {
+			return fmt.Errorf("features[%d].label: %w", i, err)
+		}
+	}
+	for i, l := range w.Labels {
+		if l == nil {
+			return fmt.Errorf("labels[%d]: missing label", i)
+		} else if err := validateJSONLabelColors(l); err != nil {
+			return fmt.Errorf("labels[%d]: %w", i, err)
+		}
+	}
+	for i, s := range w.Shapes {
+		if s == nil {
+			return fmt.Errorf("shapes[%d]: missing shape", i)
+		}
+		for j, p := range s.Points {
+			if p == nil {
+				return fmt.Errorf("shapes[%d].points[%d]: missing point", i, j)
+			}
+		}
+	}
+	for i, n := range w.Notes {
+		if n == nil {
+			return fmt.Errorf("notes[%d]: missing note", i)
+		}
+	}
+	for i, info := range w.Informations.Informations {
+		if info == nil {
+			return fmt.Errorf("informations[%d]: missing information", i)
+		}
+		for j, detail := range info.Details {
+			if detail == nil {
+				return fmt.Errorf("informations[%d].details[%d]: missing detail", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func validateJSONLabelColors(l *wxx.Label) error {
+	if l == nil {
+		return nil
+	} else if err := validateJSONColor(l.Color); err != nil {
+		return fmt.Errorf("color: %w", err)
+	} else if err = validateJSONColor(l.OutlineColor); err != nil {
+		return fmt.Errorf("outlineColor: %w", err)
+	} else if err = validateJSONColor(l.BackgroundColor); err != nil {
+		return fmt.Errorf("backgroundColor: %w", err)
+	}
+	return nil
+}
+
+// validateJSONConfiguration verifies the configuration block.
+func validateJSONConfiguration(w *wxx.Map) error {
+	for i, c := range w.Configuration.TerrainConfig {
+		if c == nil {
+			return fmt.Errorf("configuration.terrain-config[%d]: missing config", i)
+		}
+		for j, t := range c.Terrains {
+			if t == nil || t.Name == "" {
+				return fmt.Errorf("configuration.terrain-config[%d].terrains[%d]: missing name", i, j)
+			} else if err := validateJSONColor(t.Color); err != nil {
+				return fmt.Errorf("configuration.terrain-config[%d].terrains[%d].color: %w", i, j, err)
+			}
+		}
+	}
+	for i, c := range w.Configuration.FeatureConfig {
+		if c == nil {
+			return fmt.Errorf("configuration.feature-config[%d]: missing config", i)
+		}
+		for j, f := range c.Features {
+			if f == nil || f.Name == "" {
+				return fmt.Errorf("configuration.feature-config[%d].features[%d]: missing name", i, j)
+			} else if err := validateJSONColor(f.Color); err != nil {
+				return fmt.Errorf("configuration.feature-config[%d].features[%d].color: %w", i, j, err)
+			}
+		}
+	}
+	for i, c := range w.Configuration.TextureConfig {
+		if c == nil {
+			return fmt.Errorf("configuration.texture-config[%d]: missing config", i)
+		}
+		for j, t := range c.Textures {
+			if t == nil || t.Name == "" {
+				return fmt.Errorf("configuration.texture-config[%d].textures[%d]: missing name", i, j)
+			}
+		}
+	}
+	for i, ls := range w.Configuration.TextConfig.LabelStyles {
+		if ls == nil {
+			return fmt.Errorf("configuration.text-config.labelStyles[%d]: missing style", i)
+		} else if err := validateJSONColor(ls.Color); err != nil {
+			return fmt.Errorf("configuration.text-config.labelStyles[%d].color: %w", i, err)
+		} else if err = validateJSONColor(ls.BackgroundColor); err != nil {
+			return fmt.Errorf("configuration.text-config.labelStyles[%d].backgroundColor: %w", i, err)
+		} else if err = validateJSONColor(ls.OutlineColor); err != nil {
+			return fmt.Errorf("configuration.text-config.labelStyles[%d].outlineColor: %w", i, err)
+		}
+	}
+	for i, ss := range w.Configuration.ShapeConfig.ShapeStyles {
+		if ss == nil {
+			return fmt.Errorf("configuration.shape-config.shapeStyles[%d]: missing style", i)
+		} else if err := validateJSONColor(ss.StrokePaint); err != nil {
+			return fmt.Errorf("configuration.shape-config.shapeStyles[%d].strokePaint: %w", i, err)
+		} else if err = validateJSONColor(ss.FillPaint); err != nil {
+			return fmt.Errorf("configuration.shape-config.shapeStyles[%d].fillPaint: %w", i, err)
+		} else if err = validateJSONColor(ss.DsColor); err != nil {
+			return fmt.Errorf("configuration.shape-config.shapeStyles[%d].dscolor: %w", i, err)
+		} else if err = validateJSONColor(ss.InsColor); err != nil {
+			return fmt.Errorf("configuration.shape-config.shapeStyles[%d].insColor: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateJSONColor verifies that every channel of the color is in the range 0.0 to 1.0.
+// A nil color is valid; it means "use the default."
+func validateJSONColor(rgba *wxx.RGBA) error {
+	if rgba == nil {
+		return nil
+	}
+	for _, channel := range []struct {
+		name  string
+		value float64
+	}{
+		{"R", rgba.R},
+		{"G", rgba.G},
+		{"B", rgba.B},
+		{"A", rgba.A},
+	} {
+		if !(0 <= channel.value && channel.value <= 1) {
+			return fmt.Errorf("%s: invalid value %v", channel.name, channel.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONToWXX(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("..", "testdata", "golden", "features-labels.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := JSONToWXX(valid); err != nil {
+		t.Fatalf("valid: %v", err)
+	} else if m.Width() != 2 || m.Height() != 2 || len(m.Features) != 2 {
+		t.Errorf("valid: got %dx%d map with %d features, want 2x2 with 2", m.Width(), m.Height(), len(m.Features))
+	}
+
+	// each case changes one thing in the valid map
+	red := &wxx.RGBA{R: 1.5, A: 1}
+	for _, tc := range []struct {
+		name   string
+		change func(m *wxx.Map)
+		want   string // substring of the error
+		is     error  // optional error that must be wrapped
+	}{
+		{name: "missing meta-data version", change: func(m *wxx.Map) { m.MetaData.Version = "" }, want: "missing version", is: ErrInvalidMetaData},
+		{name: "malformed meta-data version", change: func(m *wxx.Map) { m.MetaData.Version = "1.x" }, want: "major.minor.patch", is: ErrInvalidMetaData},
+		{name: "newer meta-data version", change: func(m *wxx.Map) { m.MetaData.Version = "0.1.0" }, want: "supports", is: ErrUnsupportedMetaData},
+		{name: "invalid created", change: func(m *wxx.Map) { m.MetaData.Created = "yesterday" }, want: "meta-data.created", is: ErrInvalidMetaData},
+		{name: "invalid source created", change: func(m *wxx.Map) { m.MetaData.Source.Created = "yesterday" }, want: "meta-data.source.created", is: ErrInvalidMetaData},
+		{name: "missing terrain", change: func(m *wxx.Map) { m.TerrainMap.List[1] = nil }, want: "terrainMap.list[1]: missing terrain"},
+		{name: "missing terrain label", change: func(m *wxx.Map) { m.TerrainMap.List[1].Label = "" }, want: "missing label"},
+		{name: "duplicate terrain label", change: func(m *wxx.Map) { m.TerrainMap.List[1].Label = "Blank" }, want: "duplicate label"},
+		{name: "duplicate terrain index", change: func(m *wxx.Map) { m.TerrainMap.List[1].Index = 0 }, want: "already used"},
+		{name: "terrain data not in list", change: func(m *wxx.Map) { m.TerrainMap.Data["Lava"] = 9 }, want: "not in list"},
+		{name: "terrain data index mismatch", change: func(m *wxx.Map) { m.TerrainMap.Data["Blank"] = 3 }, want: "does not match"},
+		{name: "negative dimensions", change: func(m *wxx.Map) { m.Tiles.TilesHigh = -1 }, want: "invalid dimensions"},
+		{name: "tiles wide", change: func(m *wxx.Map) { m.Tiles.TilesWide = 3 }, want: "found 2 tile rows"},
+		{name: "tiles high", change: func(m *wxx.Map) { m.Tiles.TileRows[1] = m.Tiles.TileRows[1][:1] }, want: "found 1 tiles"},
+		{name: "missing tile", change: func(m *wxx.Map) { m.Tiles.TileRows[0][1] = nil }, want: "missing tile"},
+		{name: "unknown terrain", change: func(m *wxx.Map) { m.Tiles.TileRows[0][1].Terrain = 9 }, want: "not in terrain map"},
+		{name: "resource", change: func(m *wxx.Map) { m.Tiles.TileRows[0][0].Resources.Gems = 101 }, want: "gems: invalid value 101"},
+		{name: "tile color", change: func(m *wxx.Map) { m.Tiles.TileRows[0][0].CustomBackgroundColor = red }, want: "customBackgroundColor: R"},
+		{name: "missing feature", change: func(m *wxx.Map) { m.Features[1] = nil }, want: "features[1]: missing feature"},
+		{name: "feature color", change: func(m *wxx.Map) { m.Features[0].Color = red }, want: "features[0].color"},
+		{name: "feature label color", change: func(m *wxx.Map) { m.Features[0].Label.OutlineColor = red }, want: "features[0].label: outlineColor"},
+		{name: "missing label", change: func(m *wxx.Map) { m.Labels[0] = nil }, want: "labels[0]: missing label"},
+		{name: "label color", change: func(m *wxx.Map) { m.Labels[1].BackgroundColor = red }, want: "labels[1]: backgroundColor"},
+		{name: "missing shape", change: func(m *wxx.Map) { m.Shapes = []*wxx.Shape{nil} }, want: "shapes[0]: missing shape"},
+		{name: "missing point", change: func(m *wxx.Map) { m.Shapes = []*wxx.Shape{{Points: []*wxx.Point{nil}}} }, want: "points[0]: missing point"},
+		{name: "missing note", change: func(m *wxx.Map) { m.Notes = []*wxx.Note{nil} }, want: "notes[0]: missing note"},
+		{name: "missing information", change: func(m *wxx.Map) { m.Informations.Informations = []*wxx.Information{nil} }, want: "informations[0]: missing information"},
+		{name: "missing detail", change: func(m *wxx.Map) {
+			m.Informations.Informations = []*wxx.Information{{Details: []*wxx.InformationDetail{nil}}}
+		}, want: "details[0]: missing detail"},
+		{name: "missing terrain type name", change: func(m *wxx.Map) {
+			m.Configuration.TerrainConfig = []*wxx.TerrainConfig{{Terrains: []*wxx.TerrainType{{}}}}
+		}, want: "terrain-config[0].terrains[0]: missing name"},
+		{name: "terrain type color", change: func(m *wxx.Map) {
+			m.Configuration.TerrainConfig = []*wxx.TerrainConfig{{Terrains: []*wxx.TerrainType{{Name: "Lava", Color: red}}}}
+		}, want: "terrains[0].color"},
+		{name: "missing feature type name", change: func(m *wxx.Map) {
+			m.Configuration.FeatureConfig = []*wxx.FeatureConfig{{Features: []*wxx.FeatureType{{}}}}
+		}, want: "feature-config[0].features[0]: missing name"},
+		{name: "missing texture type name", change: func(m *wxx.Map) {
+			m.Configuration.TextureConfig = []*wxx.TextureConfig{{Textures: []*wxx.TextureType{nil}}}
+		}, want: "texture-config[0].textures[0]: missing name"},
+		{name: "label style color", change: func(m *wxx.Map) {
+			m.Configuration.TextConfig.LabelStyles = []*wxx.LabelStyle{{Name: "Ocean", Color: red}}
+		}, want: "labelStyles[0].color"},
+		{name: "shape style color", change: func(m *wxx.Map) {
+			m.Configuration.ShapeConfig.ShapeStyles = []*wxx.ShapeStyle{{Name: "Road", FillPaint: red}}
+		}, want: "shapeStyles[0].fillPaint"},
+	} {
+		m := &wxx.Map{}
+		if err := json.Unmarshal(valid, m); err != nil {
+			t.Fatal(err)
+		}
+		tc.change(m)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		_, err = JSONToWXX(data)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", tc.name)
+		} else if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, err, tc.want)
+		} else if tc.is != nil && !errors.Is(err, tc.is) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.is)
+		}
+	}
+}
+
+func TestJSONToWXXUnknownField(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("..", "testdata", "golden", "basic.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Replace(valid, []byte(`"hexWidth":`), []byte(`"hexWidht": 1, "hexWidth":`), 1)
+	if _, err := JSONToWXX(data); err == nil || !strings.Contains(err.Error(), "hexWidht") {
+		t.Errorf("unknown field: got %v, want error naming the field", err)
+	}
+}
+
+func TestJSONToWXXTrailingData(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("..", "testdata", "golden", "basic.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := JSONToWXX(append(bytes.Clone(valid), "\n\n"...)); err != nil {
+		t.Errorf("trailing whitespace: %v", err)
+	}
+	for name, trailer := range map[string]string{
+		"garbage":         "garbage",
+		"second document": string(valid),
+		"empty object":    "{}",
+	} {
+		if _, err := JSONToWXX(append(bytes.Clone(valid), trailer...)); err == nil || !strings.Contains(err.Error(), "after the map") {
+			t.Errorf("%s: got %v, want error", name, err)
+		}
+	}
+}
+
+func TestJSONToWXXTerrainData(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("..", "testdata", "golden", "basic.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hand-edited files may only have the terrain lookup
+	m := &wxx.Map{}
+	if err := json.Unmarshal(valid, m); err != nil {
+		t.Fatal(err)
+	}
+	m.TerrainMap.List = nil
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := JSONToWXX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, terrain := range got.TerrainMap.List {
+		if terrain.Index != i {
+			t.Errorf("list[%d]: got index %d, want %d", i, terrain.Index, i)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultVersion is the version written when the caller doesn't ask for one
+// and the map's own version has no encoder.
+const DefaultVersion = "1.73"
+
+// Codec reads and writes a single version of the Worldographer schema.
+//
+// Each version registers its codec from an init function in this package.
+// Unmarshal and Translate are needed to read the version; Encode is needed
+// to write it. A codec may register only one side.
+type Codec struct {
+	Version     string // version written by the encoder, "1.73"
+	Description string // human-readable name, "Worldographer 2022"
+
+	// Accepts returns true if the codec can read a file with the given
+	// version attribute. If nil, only Version is accepted.
+	Accepts func(version string) bool
+
+	// Unmarshal reads the version's WXML from the decoder.
+	// The start element is the root element of the document.
+	Unmarshal func(d *xml.Decoder, start xml.StartElement) (WXML, error)
+
+	// Translate converts the version's WXML to a WXX map.
+	// Warnings about the input are sent to the logger, which is never nil.
+	Translate func(wxml WXML, logger *slog.Logger) (*wxx.Map, error)
+
+	// Encode writes a WXX map as UTF-8 XML (without the XML header).
+	// The version is the version to write; it is one that the codec accepts.
+	Encode func(w io.Writer, m *wxx.Map, version string) error
+}
+
+// Capabilities describes what a registered codec can do.
+type Capabilities struct {
+	Read  bool // can convert files of this version to a WXX map
+	Write bool // can convert a WXX map to files of this version
+}
+
+// Capabilities returns the capabilities of the codec.
+func (c *Codec) Capabilities() Capabilities {
+	return Capabilities{
+		Read:  c.Unmarshal != nil && c.Translate != nil,
+		Write: c.Encode != nil,
+	}
+}
+
+// accepts returns true if the codec can read the version.
+func (c *Codec) accepts(version string) bool {
+	if c.Accepts == nil {
+		return version == c.Version
+	}
+	return c.Accepts(version)
+}
+
+var registry struct {
+	sync.RWMutex
+	codecs []*Codec
+}
+
+// Register adds a codec to the registry.
+// It returns an error if the version is already registered.
+func Register(c *Codec) error {
+	if c == nil || c.Version == "" {
+		return fmt.Errorf("register: missing version")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, r := range registry.codecs {
+		if r.Version == c.Version {
+			return fmt.Errorf("register: %s: duplicate version", c.Version)
+		}
+	}
+	registry.codecs = append(registry.codecs, c)
+	sort.Slice(registry.codecs, func(i, j int) bool {
+		return compareVersions(registry.codecs[i].Version, registry.codecs[j].Version) < 0
+	})
+	return nil
+}
+
+// compareVersions compares two dotted versions, like "1.73" and "10.0",
+// field by field. Numeric fields are compared as numbers; other fields are
+// compared as text. A missing field sorts before any other value.
+// It returns -1 if a is before b, 1 if a is after b, and 0 if they are equal.
+func compareVersions(a, b string) int {
+	fa, fb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(fa) && i < len(fb); i++ {
+		na, errA := strconv.Atoi(fa[i])
+		nb, errB := strconv.Atoi(fb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil: // numbers sort before text
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(fa[i], fb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	if len(fa) < len(fb) {
+		return -1
+	} else if len(fa) > len(fb) {
+		return 1
+	}
+	return 0
+}
+
+// mustRegister panics if the codec can't be registered.
+func mustRegister(c *Codec) {
+	if err := Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Codecs returns all registered codecs, sorted by version number.
+func Codecs() []*Codec {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]*Codec{}, registry.codecs...)
+}
+
+// LookupDecoder returns the codec that can read files with the given version attribute.
+func LookupDecoder(version string) (*Codec, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, c := range registry.codecs {
+		if c.Capabilities().Read && c.accepts(version) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", version, ErrUnsupportedVersion)
+}
+
+// LookupEncoder returns the codec that writes the given version.
+// The exact version is preferred; otherwise, the first codec that accepts it is used.
+func LookupEncoder(version string) (*Codec, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, c := range registry.codecs {
+		if c.Capabilities().Write && c.Version == version {
+			return c, nil
+		}
+	}
+	for _, c := range registry.codecs {
+		if c.Capabilities().Write && c.accepts(version) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", version, ErrUnsupportedVersion)
+}
@@ -40,6 +40,15 @@ func rgbaToNullableXmlAttr(rgba *wxx.RGBA) string {
 	return s
 }
 
+// rgbaToOptionalXmlAttr returns an empty string if the color is nil,
+// which tells the template to leave the attribute out.
+func rgbaToOptionalXmlAttr(rgba *wxx.RGBA) string {
+	if rgba == nil {
+		return ""
+	}
+	return rgbaToXmlAttr(rgba)
+}
+
 func decodeRgba(s string) (rgba *wxx.RGBA, err error) {
 	if s == "" || s == "null" || s == "0.0,0.0,0.0,1.0" {
 		return nil, nil
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"encoding/xml"
+	"github.com/mdhender/wxconv/models/tmap173"
+	"github.com/mdhender/wxconv/models/wxml173"
+	"github.com/mdhender/wxconv/models/wxx"
+	"strings"
+)
+
+// decodeUnknown copies the attributes and elements that the WXML doesn't model.
+// It returns nil if there aren't any.
+func decodeUnknown(attrs []xml.Attr, elements []wxml173.AnyElement) *wxx.Unknown {
+	if len(attrs) == 0 && len(elements) == 0 {
+		return nil
+	}
+	u := &wxx.Unknown{}
+	for _, attr := range attrs {
+		u.Attrs = append(u.Attrs, wxx.Attr{Name: xmlName(attr.Name), Value: attr.Value})
+	}
+	for _, element := range elements {
+		e := wxx.Element{Name: xmlName(element.XMLName), InnerXML: element.InnerXML, Position: element.Position}
+		for _, attr := range element.Attrs {
+			e.Attrs = append(e.Attrs, wxx.Attr{Name: xmlName(attr.Name), Value: attr.Value})
+		}
+		u.Elements = append(u.Elements, e)
+	}
+	return u
+}
+
+// mergeUnknown combines the attributes and elements of elements that are
+// repeated in the source but stored once in the WXX map. The elements of b
+// are moved past the known elements that came before them.
+func mergeUnknown(a, b *wxx.Unknown, known int) *wxx.Unknown {
+	if b == nil {
+		return a
+	}
+	u := &wxx.Unknown{Attrs: b.Attrs}
+	for _, element := range b.Elements {
+		element.Position += known
+		u.Elements = append(u.Elements, element)
+	}
+	if a == nil {
+		return u
+	}
+	return &wxx.Unknown{
+		Attrs:    append(a.Attrs, u.Attrs...),
+		Elements: append(a.Elements, u.Elements...),
+	}
+}
+
+// encodeUnknown formats the attributes and elements as XML so that the
+// templates can write them back out. Attributes are written after the
+// attributes that we model. Elements are written before the known element
+// at their position, or after the last one if the parent has fewer than
+// that many known elements.
+func encodeUnknown(u *wxx.Unknown, known int) tmap173.Unknown {
+	if u == nil {
+		return tmap173.Unknown{}
+	}
+	t := tmap173.Unknown{Attrs: attrsToXml(u.Attrs)}
+	var after []wxx.Element
+	for _, element := range u.Elements {
+		if element.Position >= known {
+			after = append(after, element)
+			continue
+		}
+		if t.Before == nil {
+			t.Before = map[int]string{}
+		}
+		t.Before[element.Position] += elementsToXml([]wxx.Element{element})
+	}
+	t.Elements = elementsToXml(after)
+	return t
+}
+
+// present returns the number of optional known elements that are present.
+func present(elements ...bool) int {
+	n := 0
+	for _, ok := range elements {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// attrsToXml formats the attributes as ` name="value"` pairs.
+func attrsToXml(attrs []wxx.Attr) string {
+	sb := &strings.Builder{}
+	for _, attr := range attrs {
+		sb.WriteByte(' ')
+		sb.WriteString(attr.Name)
+		sb.WriteString(`="`)
+		_ = xml.EscapeText(sb, []byte(attr.Value)) // strings.Builder never fails
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// elementsToXml formats the elements. The inner XML is written as is.
+func elementsToXml(elements []wxx.Element) string {
+	sb := &strings.Builder{}
+	for _, element := range elements {
+		sb.WriteByte('<')
+		sb.WriteString(element.Name)
+		sb.WriteString(attrsToXml(element.Attrs))
+		sb.WriteByte('>')
+		sb.WriteString(element.InnerXML)
+		sb.WriteString("</")
+		sb.WriteString(element.Name)
+		sb.WriteByte('>')
+	}
+	return sb.String()
+}
+
+// xmlName returns the name as it would be written in the source.
+// The WXML decoder puts the namespace prefix, not the URI, in Space.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
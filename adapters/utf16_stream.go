@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NewUTF16ToUTF8Reader returns a reader that converts a stream of big-endian
+// UTF-16 data to UTF-8 as it is read. The stream must start with a BOM.
+//
+// It is the streaming version of UTF16ToUTF8 and returns the same errors,
+// although an odd number of bytes is only reported when the end of the
+// stream is reached.
+func NewUTF16ToUTF8Reader(r io.Reader) io.Reader {
+	return &utf16Reader{r: bufio.NewReader(r)}
+}
+
+type utf16Reader struct {
+	r       *bufio.Reader
+	hasBOM  bool   // true once the BOM has been verified
+	pending rune   // high surrogate waiting for the low surrogate
+	buf     []byte // converted data that hasn't been read yet
+	err     error  // sticky error, returned once buf is drained
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.buf) == 0 && u.err == nil {
+		u.fill()
+	}
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+	if n == 0 {
+		return 0, u.err
+	}
+	return n, nil
+}
+
+// fill converts the next chunk of input into buf.
+func (u *utf16Reader) fill() {
+	if !u.hasBOM {
+		var bom [2]byte
+		if n, err := io.ReadFull(u.r, bom[:]); err != nil {
+			if n == 1 {
+				u.err = ErrMissingFinalByte
+			} else if err == io.EOF {
+				u.err = ErrMissingBOM
+			} else {
+				u.err = err
+			}
+			return
+		}
+		if bom[0] == 0xfe && bom[1] == 0xff {
+			// as expected
+		} else if bom[0] == 0xff && bom[1] == 0xfe {
+			u.err = ErrNotBigEndianUTF16Encoded
+			return
+		} else {
+			u.err = ErrMissingBOM
+			return
+		}
+		u.hasBOM = true
+	}
+
+	var utfBuffer [utf8.UTFMax]byte
+	emit := func(r rune) {
+		n := utf8.EncodeRune(utfBuffer[:], r)
+		u.buf = append(u.buf, utfBuffer[:n]...)
+	}
+	for i := 0; i < 4096; i++ {
+		hi, err := u.r.ReadByte()
+		if err != nil {
+			if u.pending != 0 {
+				emit(utf8.RuneError)
+				u.pending = 0
+			}
+			u.err = err
+			return
+		}
+		lo, err := u.r.ReadByte()
+		if err == io.EOF {
+			u.err = ErrMissingFinalByte
+			return
+		} else if err != nil {
+			u.err = err
+			return
+		}
+		r := rune(hi)<<8 | rune(lo)
+		if u.pending != 0 {
+			if 0xdc00 <= r && r < 0xe000 {
+				emit(utf16.DecodeRune(u.pending, r))
+				u.pending = 0
+				continue
+			}
+			// the high surrogate was not followed by a low surrogate
+			emit(utf8.RuneError)
+			u.pending = 0
+		}
+		if 0xd800 <= r && r < 0xdc00 {
+			u.pending = r
+		} else if 0xdc00 <= r && r < 0xe000 {
+			emit(utf8.RuneError)
+		} else {
+			emit(r)
+		}
+	}
+}
+
+// NewUTF8ToUTF16Writer returns a writer that converts UTF-8 data to big-endian
+// UTF-16 data as it is written. The BOM is written before the first data.
+//
+// It is the streaming version of UTF8ToUTF16. Close must be called to
+// report data that ends in the middle of a rune; it does not close w.
+func NewUTF8ToUTF16Writer(w io.Writer) io.WriteCloser {
+	return &utf16Writer{w: w}
+}
+
+type utf16Writer struct {
+	w       io.Writer
+	hasBOM  bool   // true once the BOM has been written
+	partial []byte // incomplete rune from the end of the last write
+	buf     []byte // reusable output buffer
+}
+
+func (u *utf16Writer) Write(p []byte) (int, error) {
+	if !u.hasBOM {
+		if _, err := u.w.Write([]byte{0xfe, 0xff}); err != nil {
+			return 0, err
+		}
+		u.hasBOM = true
+	}
+
+	src := p
+	if len(u.partial) != 0 {
+		src = append(u.partial, p...)
+		u.partial = nil
+	}
+
+	u.buf = u.buf[:0]
+	for len(src) > 0 {
+		if !utf8.FullRune(src) {
+			// save the start of the rune for the next write
+			u.partial = append([]byte{}, src...)
+			break
+		}
+		// extract next rune from the source
+		r, w := utf8.DecodeRune(src)
+		if r == utf8.RuneError && w <= 1 {
+			return 0, fmt.Errorf("invalid utf8 data")
+		}
+		// consume that rune
+		src = src[w:]
+		// convert the rune to UTF-16 and add it to the results
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError || r2 != utf8.RuneError {
+			u.buf = append(u.buf, byte(r1>>8), byte(r1), byte(r2>>8), byte(r2))
+		} else {
+			u.buf = append(u.buf, byte(r>>8), byte(r))
+		}
+	}
+	if _, err := u.w.Write(u.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close reports an error if the data ended in the middle of a rune.
+// It does not close the underlying writer.
+func (u *utf16Writer) Close() error {
+	if len(u.partial) != 0 {
+		return fmt.Errorf("invalid utf8 data")
+	}
+	return nil
+}
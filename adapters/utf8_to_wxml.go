@@ -5,41 +5,69 @@ package adapters
 import (
 	"bytes"
 	"encoding/xml"
-	"github.com/mdhender/wxconv/models/wxml173"
+	"io"
+	"strings"
 )
 
+// xmlHeader is the header that Worldographer writes at the start of the XML.
+const xmlHeader = "<?xml version='1.0' encoding='utf-16'?>\n"
+
 type WXML interface {
 	BaseVersion() string
 }
 
 // UTF8ToWXML converts the data to the associated version of WXML.
+// The version is read from the data and used to find the registered codec.
 func UTF8ToWXML(data []byte) (WXML, error) {
 	// verify the xml header
-	xmlHeader := []byte("<?xml version='1.0' encoding='utf-16'?>\n")
-	if !bytes.HasPrefix(data, xmlHeader) {
+	if !bytes.HasPrefix(data, []byte(xmlHeader)) {
 		return nil, ErrMissingXMLHeader
 	}
+	return ReadWXML(bytes.NewReader(data))
+}
 
-	// remove the xml header
-	data = data[len(xmlHeader):]
-
-	// read the version from the xml data
-	var version struct {
-		Version string `xml:"version,attr"`
+// ReadWXML reads UTF-8 XML from the stream and converts it to the associated
+// version of WXML. It is the streaming version of UTF8ToWXML.
+//
+// The stream must start with the XML header. The header declares the
+// encoding as UTF-16; we assume that the caller has already converted
+// the data to UTF-8.
+func ReadWXML(r io.Reader) (WXML, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.ToLower(charset) != "utf-16" {
+			return nil, ErrMissingXMLHeader
+		}
+		return input, nil
 	}
-	if err := xml.Unmarshal(data, &version); err != nil {
+
+	// the first token must be the xml header
+	if tok, err := d.Token(); err != nil {
 		return nil, err
+	} else if pi, ok := tok.(xml.ProcInst); !ok || pi.Target != "xml" {
+		return nil, ErrMissingXMLHeader
 	}
-	// log.Printf("UTF8ToWXML: read version %+v\n", version)
 
-	switch version.Version {
-	case "1.73":
-		srcMap := &wxml173.Map{}
-		// convert from xml to a structure that's built just for the conversion
-		if err := xml.Unmarshal(data, &srcMap); err != nil {
+	// skip to the first element and read the version from it
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var version string
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "version" {
+				version = attr.Value
+			}
+		}
+		codec, err := LookupDecoder(version)
+		if err != nil {
 			return nil, err
 		}
-		return srcMap, nil
+		return codec.Unmarshal(d, start)
 	}
-	return nil, ErrUnsupportedVersion
 }
@@ -69,6 +69,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 	t.GridAndNumbering.NumberPosition = w.GridAndNumbering.NumberPosition                                  // "BOTTOM"
 	t.GridAndNumbering.NumberPrePad = w.GridAndNumbering.NumberPrePad                                      // "DOUBLE_ZERO"
 	t.GridAndNumbering.NumberSeparator = w.GridAndNumbering.NumberSeparator                                // "."
+	t.GridAndNumbering.Unknown = encodeUnknown(w.GridAndNumbering.Unknown, 0)
 
 	// terrainLookup will be used later to link terrain names to terrain indexes
 	terrainLookup := make(map[int]string)
@@ -82,16 +83,22 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 	}
 
 	for _, v := range w.MapLayer {
-		t.MapLayer = append(t.MapLayer, tmap173.MapLayer{Name: v.Name, IsVisible: v.IsVisible})
+		t.MapLayer = append(t.MapLayer, tmap173.MapLayer{Name: v.Name, IsVisible: v.IsVisible, Unknown: encodeUnknown(v.Unknown, 0)})
 	}
 
 	t.Tiles.ViewLevel = w.Tiles.ViewLevel
 	t.Tiles.TilesWide = fmt.Sprintf("%d", w.Tiles.TilesWide)
 	t.Tiles.TilesHigh = fmt.Sprintf("%d", w.Tiles.TilesHigh)
+	t.Tiles.Unknown = encodeUnknown(w.Tiles.Unknown, w.Width())
 
-	for _, tileRow := range w.Tiles.TileRows {
+	// each tilerow is a column of tiles, regardless of the orientation
+	for col := 0; col < w.Width(); col++ {
 		sb := strings.Builder{}
-		for _, tile := range tileRow {
+		for row := 0; row < w.Height(); row++ {
+			tile := w.TileAt(col, row)
+			if tile == nil {
+				return nil, fmt.Errorf("tiles: %d,%d: missing tile", col, row)
+			}
 			terrainIndex := tile.Terrain
 			elevation := strings.TrimSuffix(FToXF(tile.Elevation), ".0")
 			isIcy := "0"
@@ -145,6 +152,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 	t.MapKey.EntryFontBold = fmt.Sprintf("%v", w.MapKey.EntryFontBold)
 	t.MapKey.EntryFontItalic = fmt.Sprintf("%v", w.MapKey.EntryFontItalic)
 	t.MapKey.EntryScale = strings.TrimSuffix(FToXF(w.MapKey.EntryScale), ".0")
+	t.MapKey.Unknown = encodeUnknown(w.MapKey.Unknown, 0)
 
 	for _, feature := range w.Features {
 		tf := &tmap173.Feature{
@@ -175,6 +183,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 				ViewLevel: feature.Location.ViewLevel,
 				X:         FToXF(feature.Location.X),
 				Y:         FToXF(feature.Location.Y),
+				Unknown:   encodeUnknown(feature.Location.Unknown, 0),
 			}
 		}
 		if feature.Label != nil {
@@ -195,6 +204,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 				IsGMOnly:        fmt.Sprintf("%v", feature.Label.IsGMOnly),
 				Tags:            feature.Label.Tags,
 				BackgroundColor: rgbaToXmlAttr(feature.Label.BackgroundColor),
+				Unknown:         encodeUnknown(feature.Label.Unknown, present(feature.Label.Location != nil)),
 			}
 			if feature.Label.Location != nil {
 				tf.Label.Location = &tmap173.LabelLocation{
@@ -202,9 +212,12 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 					X:         FToXF(feature.Label.Location.X),
 					Y:         FToXF(feature.Label.Location.Y),
 					Scale:     FToXF(feature.Label.Location.Scale),
+					Unknown:   encodeUnknown(feature.Label.Location.Unknown, 0),
 				}
 			}
+			tf.Label.InnerText = feature.Label.InnerText
 		}
+		tf.Unknown = encodeUnknown(feature.Unknown, present(tf.Location != nil, tf.Label != nil))
 		t.Features = append(t.Features, tf)
 	}
 
@@ -225,6 +238,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 			IsProvince:   fmt.Sprintf("%v", wLabel.IsProvince),
 			IsGMOnly:     fmt.Sprintf("%v", wLabel.IsGMOnly),
 			Tags:         wLabel.Tags,
+			Unknown:      encodeUnknown(wLabel.Unknown, present(wLabel.Location != nil)),
 		}
 		if wLabel.BackgroundColor != nil {
 			tLabel.BackgroundColor = rgbaToXmlAttr(wLabel.BackgroundColor)
@@ -235,9 +249,10 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 				X:         FToXF(wLabel.Location.X),
 				Y:         FToXF(wLabel.Location.Y),
 				Scale:     FToXF(wLabel.Location.Scale),
+				Unknown:   encodeUnknown(wLabel.Location.Unknown, 0),
 			}
 		}
-		tLabel.InnerText = strings.ReplaceAll(wLabel.InnerText, "\n", "&#10;")
+		tLabel.InnerText = wLabel.InnerText
 		t.Labels = append(t.Labels, tLabel)
 	}
 
@@ -282,12 +297,14 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 			StrokeWidth:           FToXF(wShape.StrokeWidth),
 			Tags:                  wShape.Tags,
 			Type:                  wShape.Type,
+			Unknown:               encodeUnknown(wShape.Unknown, len(wShape.Points)),
 		}
 		for _, wPoint := range wShape.Points {
 			tShape.Points = append(tShape.Points, &tmap173.Point{
-				Type: wPoint.Type,
-				X:    FToXF(wPoint.X),
-				Y:    FToXF(wPoint.Y),
+				Type:    wPoint.Type,
+				X:       FToXF(wPoint.X),
+				Y:       FToXF(wPoint.Y),
+				Unknown: encodeUnknown(wPoint.Unknown, 0),
 			})
 		}
 		t.Shapes = append(t.Shapes, tShape)
@@ -296,6 +313,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 	for _, wNote := range w.Notes {
 		tNote := &tmap173.Note{
 			InnerText: wNote.InnerText,
+			Unknown:   encodeUnknown(wNote.Unknown, 0),
 		}
 		t.Notes = append(t.Notes, tNote)
 	}
@@ -313,7 +331,8 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 			Culture:      wInformation.Culture,
 			HolySymbol:   wInformation.HolySymbol,
 			Domains:      wInformation.Domains,
-			InnerText:    strings.TrimSpace(wInformation.InnerText),
+			InnerText:    wInformation.InnerText,
+			Unknown:      encodeUnknown(wInformation.Unknown, len(wInformation.Details)),
 		}
 		for _, wDetail := range wInformation.Details {
 			tDetail := &tmap173.InformationDetail{
@@ -328,18 +347,67 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 				Culture:      wDetail.Culture,
 				HolySymbol:   wDetail.HolySymbol,
 				Domains:      wDetail.Domains,
-				InnerText:    strings.TrimSpace(wDetail.InnerText),
+				InnerText:    wDetail.InnerText,
+				Unknown:      encodeUnknown(wDetail.Unknown, 0),
 			}
 			tInformation.Details = append(tInformation.Details, tDetail)
 		}
 		t.Information = append(t.Information, tInformation)
 	}
 	t.InformationInnerText = w.Informations.InnerText
+	t.UnknownInformations = encodeUnknown(w.Informations.Unknown, len(w.Informations.Informations))
 
 	// copy over configuration
 	// copy over configuration.terrain-config
+	for _, wTerrainConfig := range w.Configuration.TerrainConfig {
+		tTerrainConfig := &tmap173.TerrainConfig{
+			InnerText: wTerrainConfig.InnerText,
+			Unknown:   encodeUnknown(wTerrainConfig.Unknown, len(wTerrainConfig.Terrains)),
+		}
+		for _, wTerrain := range wTerrainConfig.Terrains {
+			tTerrainConfig.Terrains = append(tTerrainConfig.Terrains, &tmap173.TerrainType{
+				Name:    wTerrain.Name,
+				Image:   wTerrain.Image,
+				Color:   rgbaToOptionalXmlAttr(wTerrain.Color),
+				Tags:    wTerrain.Tags,
+				Unknown: encodeUnknown(wTerrain.Unknown, 0),
+			})
+		}
+		t.Configuration.TerrainConfig = append(t.Configuration.TerrainConfig, tTerrainConfig)
+	}
 	// copy over configuration.feature-config
+	for _, wFeatureConfig := range w.Configuration.FeatureConfig {
+		tFeatureConfig := &tmap173.FeatureConfig{
+			InnerText: wFeatureConfig.InnerText,
+			Unknown:   encodeUnknown(wFeatureConfig.Unknown, len(wFeatureConfig.Features)),
+		}
+		for _, wFeature := range wFeatureConfig.Features {
+			tFeatureConfig.Features = append(tFeatureConfig.Features, &tmap173.FeatureType{
+				Name:    wFeature.Name,
+				Image:   wFeature.Image,
+				Color:   rgbaToOptionalXmlAttr(wFeature.Color),
+				Tags:    wFeature.Tags,
+				Unknown: encodeUnknown(wFeature.Unknown, 0),
+			})
+		}
+		t.Configuration.FeatureConfig = append(t.Configuration.FeatureConfig, tFeatureConfig)
+	}
 	// copy over configuration.texture-config
+	for _, wTextureConfig := range w.Configuration.TextureConfig {
+		tTextureConfig := &tmap173.TextureConfig{
+			InnerText: wTextureConfig.InnerText,
+			Unknown:   encodeUnknown(wTextureConfig.Unknown, len(wTextureConfig.Textures)),
+		}
+		for _, wTexture := range wTextureConfig.Textures {
+			tTextureConfig.Textures = append(tTextureConfig.Textures, &tmap173.TextureType{
+				Name:    wTexture.Name,
+				Image:   wTexture.Image,
+				Tags:    wTexture.Tags,
+				Unknown: encodeUnknown(wTexture.Unknown, 0),
+			})
+		}
+		t.Configuration.TextureConfig = append(t.Configuration.TextureConfig, tTextureConfig)
+	}
 	// copy over configuration.text-config
 	for _, wLabelStyle := range w.Configuration.TextConfig.LabelStyles {
 		tLabelStyle := &tmap173.LabelStyle{
@@ -351,6 +419,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 			Color:           rgbaToXmlAttr(wLabelStyle.Color),
 			BackgroundColor: rgbaToNullableXmlAttr(wLabelStyle.BackgroundColor),
 			OutlineSize:     FToXF(wLabelStyle.OutlineSize),
+			Unknown:         encodeUnknown(wLabelStyle.Unknown, 0),
 		}
 		if wLabelStyle.OutlineColor == nil {
 			tLabelStyle.OutlineColor = "null"
@@ -363,6 +432,7 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 		t.Configuration.TextConfig.LabelStyles = append(t.Configuration.TextConfig.LabelStyles, tLabelStyle)
 	}
 	t.Configuration.TextConfig.InnerText = w.Configuration.TextConfig.InnerText
+	t.Configuration.TextConfig.Unknown = encodeUnknown(w.Configuration.TextConfig.Unknown, len(w.Configuration.TextConfig.LabelStyles))
 	// copy over configuration.shape-config
 	for _, wShapeStyle := range w.Configuration.ShapeConfig.ShapeStyles {
 		tShapeStyle := &tmap173.ShapeStyle{
@@ -393,11 +463,34 @@ func WMAPToTMAPv173(w *wxx.Map) (*tmap173.Map, error) {
 			FillPaint:     rgbaToNullableXmlAttr(wShapeStyle.FillPaint),
 			DsColor:       rgbaToNullableXmlAttr(wShapeStyle.DsColor),
 			InsColor:      rgbaToNullableXmlAttr(wShapeStyle.InsColor),
+			Unknown:       encodeUnknown(wShapeStyle.Unknown, 0),
 		}
 		t.Configuration.ShapeConfig.ShapeStyles = append(t.Configuration.ShapeConfig.ShapeStyles, tShapeStyle)
 	}
 	t.Configuration.ShapeConfig.InnerText = w.Configuration.ShapeConfig.InnerText
+	t.Configuration.ShapeConfig.Unknown = encodeUnknown(w.Configuration.ShapeConfig.Unknown, len(w.Configuration.ShapeConfig.ShapeStyles))
 	t.Configuration.InnerText = w.Configuration.InnerText
+	// worldographer expects each of the configs, even if it is empty
+	if len(t.Configuration.TerrainConfig) == 0 {
+		t.Configuration.TerrainConfig = append(t.Configuration.TerrainConfig, &tmap173.TerrainConfig{})
+	}
+	if len(t.Configuration.FeatureConfig) == 0 {
+		t.Configuration.FeatureConfig = append(t.Configuration.FeatureConfig, &tmap173.FeatureConfig{})
+	}
+	if len(t.Configuration.TextureConfig) == 0 {
+		t.Configuration.TextureConfig = append(t.Configuration.TextureConfig, &tmap173.TextureConfig{})
+	}
+	// the text-config and shape-config are always written
+	known := len(t.Configuration.TerrainConfig) + len(t.Configuration.FeatureConfig) + len(t.Configuration.TextureConfig) + 2
+	t.Configuration.Unknown = encodeUnknown(w.Configuration.Unknown, known)
+
+	// gridandnumbering, terrainmap, the map layers, tiles, mapkey, features,
+	// labels, shapes, notes, informations and configuration
+	t.Unknown = encodeUnknown(w.Unknown, len(t.MapLayer)+10)
+	t.UnknownFeatures = encodeUnknown(w.UnknownFeatures, len(t.Features))
+	t.UnknownLabels = encodeUnknown(w.UnknownLabels, len(t.Labels))
+	t.UnknownShapes = encodeUnknown(w.UnknownShapes, len(t.Shapes))
+	t.UnknownNotes = encodeUnknown(w.UnknownNotes, len(t.Notes))
 
 	return t, nil
 }
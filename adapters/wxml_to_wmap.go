@@ -5,8 +5,10 @@ package adapters
 import (
 	"fmt"
 	"github.com/mdhender/wxconv/models/wxml173"
+	"github.com/mdhender/wxconv/models/wxml200"
 	"github.com/mdhender/wxconv/models/wxx"
-	"log"
+	"io"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
@@ -15,20 +17,34 @@ import (
 // WXMLToWXX translates any known WXML mapping to the current WXX mapping.
 // It returns an error if the input is not a known WXML mapping or
 // if there are errors translating between the two mappings.
-// Panics is the input is not a WXML mapping.
-func WXMLToWXX(wxml WXML) (*wxx.Map, error) {
-	switch m := wxml.(type) {
-	case *wxml173.Map:
-		return wxmlV173ToWXX(m)
+// Warnings are sent to the logger; if it is nil, they are discarded.
+func WXMLToWXX(wxml WXML, logger *slog.Logger) (*wxx.Map, error) {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
-	panic(fmt.Sprintf("assert(wxml.type != %T)", wxml))
+	if wxml == nil {
+		return nil, ErrUnsupportedWXMLVersion
+	}
+	codec, err := LookupDecoder(wxml.BaseVersion())
+	if err != nil {
+		return nil, err
+	}
+	return codec.Translate(wxml, logger)
+}
+
+// wxmlV200ToWXX translates a v2.x mapping. The v2.x elements are read with
+// the v1.73 element types, so we reuse that translation. The version attribute
+// is copied from the source, so the result remembers that it was a v2.x map,
+// but it is exported as adapters.DefaultVersion since we can't write v2.x.
+func wxmlV200ToWXX(m *wxml200.Map, logger *slog.Logger) (*wxx.Map, error) {
+	return wxmlV173ToWXX((*wxml173.Map)(m), logger)
 }
 
-func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
+func wxmlV173ToWXX(m *wxml173.Map, logger *slog.Logger) (*wxx.Map, error) {
 	var err error
 
 	w := &wxx.Map{}
-	w.MetaData.Version = "0.0.1"
+	w.MetaData.Version = wxx.MetaDataVersion
 	w.MetaData.Created = time.Now().UTC().Format(time.RFC3339)
 	w.MetaData.Source.Name = "unknown"
 	w.MetaData.Source.Created = "0001-01-01T00:00:00Z"
@@ -37,8 +53,8 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 	w.ContinentToKingdomHOffset = m.ContinentToKingdomHOffset
 	w.ContinentToKingdomVOffset = m.ContinentToKingdomVOffset
 	w.HexHeight = m.HexHeight
-	if m.HexOrientation != "COLUMNS" {
-		log.Printf("warning: calculations for x,y coords do not work for %q\n", m.HexOrientation)
+	if m.HexOrientation != wxx.OrientationColumns && m.HexOrientation != wxx.OrientationRows {
+		logger.Warn("unknown hex orientation", "hexOrientation", m.HexOrientation)
 	}
 	w.HexOrientation = m.HexOrientation
 	w.HexWidth = m.HexWidth
@@ -52,12 +68,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 	w.ShowGMOnly = m.ShowGMOnly
 	w.ShowGMOnlyGlow = m.ShowGMOnlyGlow
 	w.ShowGrid = m.ShowGrid
-	if m.ShowGridNumbers == false {
-		log.Printf("todo: showGridNumber overriden to 'true'\n")
-		w.ShowGridNumbers = true
-	} else {
-		w.ShowGridNumbers = m.ShowGridNumbers
-	}
+	w.ShowGridNumbers = m.ShowGridNumbers
 	w.ShowNotes = m.ShowNotes
 	w.ShowShadows = m.ShowShadows
 	w.TriangleSize = m.TriangleSize
@@ -97,172 +108,88 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 	w.GridAndNumbering.NumberPosition = m.GridAndNumbering.NumberPosition
 	w.GridAndNumbering.NumberPrePad = m.GridAndNumbering.NumberPrePad
 	w.GridAndNumbering.NumberSeparator = m.GridAndNumbering.NumberSeparator
+	w.GridAndNumbering.Unknown = decodeUnknown(m.GridAndNumbering.UnknownAttrs, m.GridAndNumbering.UnknownElements)
 
 	// convert terrain map. in the source, the terrain key and values are
 	// stored as tab delimited columns.
+	if w.TerrainMap.List, err = decodeTerrainMap(m.TerrainMap.InnerText); err != nil {
+		return w, err
+	}
 	w.TerrainMap.Data = map[string]int{}
-	if fields := strings.Split(m.TerrainMap.InnerText, "\t"); len(fields)%2 != 0 {
-		return w, fmt.Errorf("expected even number of fields, got odd")
-	} else {
-		for len(fields) != 0 {
-			t := &wxx.Terrain{
-				Label: fields[0],
-			}
-			t.Index, err = strconv.Atoi(fields[1])
-			if err != nil {
-				return w, fmt.Errorf("field: %s: invalid index: %w", fields[0], err)
-			}
-			w.TerrainMap.List = append(w.TerrainMap.List, t)
-			w.TerrainMap.Data[t.Label] = t.Index
-			fields = fields[2:]
-		}
+	for _, t := range w.TerrainMap.List {
+		w.TerrainMap.Data[t.Label] = t.Index
 	}
 
 	for _, layer := range m.MapLayers {
-		w.MapLayer = append(w.MapLayer, wxx.MapLayer{Name: layer.Name, IsVisible: layer.IsVisible})
+		w.MapLayer = append(w.MapLayer, wxx.MapLayer{Name: layer.Name, IsVisible: layer.IsVisible, Unknown: decodeUnknown(layer.UnknownAttrs, layer.UnknownElements)})
 	}
 
 	w.Tiles.ViewLevel = m.Tiles.ViewLevel
 	w.Tiles.TilesWide = m.Tiles.TilesWide
 	w.Tiles.TilesHigh = m.Tiles.TilesHigh
-	log.Printf("hey, tilesHigh is %d tilesWide is %d\n", m.Tiles.TilesHigh, m.Tiles.TilesWide)
-	isFirstTileRow := true
+	w.Tiles.Unknown = decodeUnknown(m.Tiles.UnknownAttrs, m.Tiles.UnknownElements)
+	logger.Debug("tiles", "tilesHigh", m.Tiles.TilesHigh, "tilesWide", m.Tiles.TilesWide)
+	if w.Tiles.TilesHigh < 0 {
+		return w, fmt.Errorf("tiles: tilesHigh: %w", fmt.Errorf("invalid value"))
+	}
+	// each tilerow is a column of tiles, regardless of the orientation
+	if len(m.Tiles.TileRows) != w.Tiles.TilesWide {
+		return w, fmt.Errorf("tiles: expected %d tilerows, got %d", w.Tiles.TilesWide, len(m.Tiles.TileRows))
+	}
 	for _, tilerow := range m.Tiles.TileRows {
 		x, y := len(w.Tiles.TileRows), 0
-		w.Tiles.TileRows = append(w.Tiles.TileRows, make([]*wxx.Tile, w.Tiles.TilesHigh))
+		w.Tiles.TileRows = append(w.Tiles.TileRows, nil)
 		for _, line := range strings.Split(tilerow.InnerText, "\n") {
 			if len(line) == 0 { // ignore blank lines
 				continue
+			} else if y == w.Tiles.TilesHigh {
+				return w, fmt.Errorf("tilerow %d: expected %d tiles, got more", x, w.Tiles.TilesHigh)
 			}
-			isXEdge, isYEdge := x == 0, y == 0
-			t := &wxx.Tile{Row: x, Column: y}
-			w.Tiles.TileRows[x][y] = t
-			y++
-			// values are TerrainMapIndex Elevation IsIcy IsGMOnly Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
-			values := strings.Split(line, "\t")
-			//fmt.Printf("tilerow: %d %d: len(inner) %d lines %d line %d values %d\n", r, i+1, len(element.InnerText), len(lines), len(line), len(values))
-			switch len(values) {
-			case 6, 7, 11, 12: // allowed
-			default:
-				return w, fmt.Errorf("values: expected 6/7/11/12, got %d", len(values))
-			}
-			if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
-				return w, fmt.Errorf("value: terrainType: %w", err)
-			}
-			if isFirstTileRow {
-				// log.Printf("todo: overriding terrain for firstTileRow\n")
-				t.Terrain = 1
-			} else if x == y {
-				t.Terrain = 1
-			} else if isXEdge {
-				//log.Printf("todo: overriding terrain for x edge\n")
-				//t.Terrain = 1
-			} else if isYEdge {
-				//log.Printf("todo: overriding terrain for y edge\n")
-				//t.Terrain = 7
-			}
-			if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
-				return w, fmt.Errorf("value: elevation: %w", err)
-			}
-			t.IsIcy = values[2] == "1"
-			t.IsGMOnly = values[3] == "1"
-			if t.Resources.Animal, err = strconv.Atoi(values[4]); err != nil {
-				return w, fmt.Errorf("value: animals: %w", err)
-			} else if t.Resources.Animal < 0 {
-				return w, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
-			} else if t.Resources.Animal > 100 {
-				return w, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
-			}
-			if len(values) == 6 || len(values) == 7 {
-				if values[5] != "Z" {
-					return w, fmt.Errorf("value: sentinel: %w", fmt.Errorf("invalid value"))
-				}
-			} else {
-				if t.Resources.Brick, err = strconv.Atoi(values[5]); err != nil {
-					return w, fmt.Errorf("value: brick: %q: %w", values, err)
-				} else if t.Resources.Brick < 0 {
-					return w, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Brick > 100 {
-					return w, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Crops, err = strconv.Atoi(values[6]); err != nil {
-					return w, fmt.Errorf("value: crops: %w", err)
-				} else if t.Resources.Crops < 0 {
-					return w, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Crops > 100 {
-					return w, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Gems, err = strconv.Atoi(values[7]); err != nil {
-					return w, fmt.Errorf("value: gems: %w", err)
-				} else if t.Resources.Gems < 0 {
-					return w, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Gems > 100 {
-					return w, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Lumber, err = strconv.Atoi(values[8]); err != nil {
-					return w, fmt.Errorf("value: lumber: %w", err)
-				} else if t.Resources.Lumber < 0 {
-					return w, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Lumber > 100 {
-					return w, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Metals, err = strconv.Atoi(values[9]); err != nil {
-					return w, fmt.Errorf("value: metals: %w", err)
-				} else if t.Resources.Metals < 0 {
-					return w, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Metals > 100 {
-					return w, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Rock, err = strconv.Atoi(values[10]); err != nil {
-					return w, fmt.Errorf("value: rock: %w", err)
-				} else if t.Resources.Rock < 0 {
-					return w, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Rock > 100 {
-					return w, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				}
-			}
-			if len(values) == 7 || len(values) == 12 {
-				// split rgba
-				if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
-					return w, fmt.Errorf("value: rgba: %w", err)
-				}
+			t, err := decodeTile(line)
+			if err != nil {
+				return w, fmt.Errorf("tilerow %d: tile %d: %w", x, y, err)
 			}
+			t.Column, t.Row = x, y
+			w.Tiles.TileRows[x] = append(w.Tiles.TileRows[x], t)
+			y++
 		}
+		if y != w.Tiles.TilesHigh {
+			return w, fmt.Errorf("tilerow %d: expected %d tiles, got %d", x, w.Tiles.TilesHigh, y)
+		}
+	}
 
-		w.MapKey.PositionX = m.MapKey.PositionX
-		w.MapKey.PositionY = m.MapKey.PositionY
-		w.MapKey.Viewlevel = m.MapKey.Viewlevel
-		w.MapKey.Height = m.MapKey.Height
-		if w.MapKey.BackgroundColor, err = decodeRgba(m.MapKey.BackgroundColor); err != nil {
-			return w, fmt.Errorf("mapkey.backgroundcolor: %w", err)
-		}
-		w.MapKey.BackgroundOpacity = m.MapKey.BackgroundOpacity
-		w.MapKey.TitleText = m.MapKey.TitleText
-		w.MapKey.TitleFontFace = m.MapKey.TitleFontFace
-		if w.MapKey.TitleFontColor, err = decodeRgba(m.MapKey.TitleFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.titleFontColor: %w", err)
-		}
-		w.MapKey.TitleFontBold = m.MapKey.TitleFontBold
-		w.MapKey.TitleFontItalic = m.MapKey.TitleFontItalic
-		w.MapKey.TitleScale = m.MapKey.TitleScale
-		w.MapKey.ScaleText = m.MapKey.ScaleText
-		w.MapKey.ScaleFontFace = m.MapKey.ScaleFontFace
-		if w.MapKey.ScaleFontColor, err = decodeRgba(m.MapKey.ScaleFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.scaleFontColor: %w", err)
-		}
-		w.MapKey.ScaleFontBold = m.MapKey.ScaleFontBold
-		w.MapKey.ScaleFontItalic = m.MapKey.ScaleFontItalic
-		w.MapKey.ScaleScale = m.MapKey.ScaleScale
-		w.MapKey.EntryFontFace = m.MapKey.EntryFontFace
-		if w.MapKey.EntryFontColor, err = decodeRgba(m.MapKey.EntryFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.entryFontColor: %w", err)
-		}
-		w.MapKey.EntryFontBold = m.MapKey.EntryFontBold
-		w.MapKey.EntryFontItalic = m.MapKey.EntryFontItalic
-		w.MapKey.EntryScale = m.MapKey.EntryScale
-
-		isFirstTileRow = false
+	w.MapKey.PositionX = m.MapKey.PositionX
+	w.MapKey.PositionY = m.MapKey.PositionY
+	w.MapKey.Viewlevel = m.MapKey.Viewlevel
+	w.MapKey.Height = m.MapKey.Height
+	if w.MapKey.BackgroundColor, err = decodeRgba(m.MapKey.BackgroundColor); err != nil {
+		return w, fmt.Errorf("mapkey.backgroundcolor: %w", err)
+	}
+	w.MapKey.BackgroundOpacity = m.MapKey.BackgroundOpacity
+	w.MapKey.TitleText = m.MapKey.TitleText
+	w.MapKey.TitleFontFace = m.MapKey.TitleFontFace
+	if w.MapKey.TitleFontColor, err = decodeRgba(m.MapKey.TitleFontColor); err != nil {
+		return w, fmt.Errorf("mapkey.titleFontColor: %w", err)
+	}
+	w.MapKey.TitleFontBold = m.MapKey.TitleFontBold
+	w.MapKey.TitleFontItalic = m.MapKey.TitleFontItalic
+	w.MapKey.TitleScale = m.MapKey.TitleScale
+	w.MapKey.ScaleText = m.MapKey.ScaleText
+	w.MapKey.ScaleFontFace = m.MapKey.ScaleFontFace
+	if w.MapKey.ScaleFontColor, err = decodeRgba(m.MapKey.ScaleFontColor); err != nil {
+		return w, fmt.Errorf("mapkey.scaleFontColor: %w", err)
 	}
+	w.MapKey.ScaleFontBold = m.MapKey.ScaleFontBold
+	w.MapKey.ScaleFontItalic = m.MapKey.ScaleFontItalic
+	w.MapKey.ScaleScale = m.MapKey.ScaleScale
+	w.MapKey.EntryFontFace = m.MapKey.EntryFontFace
+	if w.MapKey.EntryFontColor, err = decodeRgba(m.MapKey.EntryFontColor); err != nil {
+		return w, fmt.Errorf("mapkey.entryFontColor: %w", err)
+	}
+	w.MapKey.EntryFontBold = m.MapKey.EntryFontBold
+	w.MapKey.EntryFontItalic = m.MapKey.EntryFontItalic
+	w.MapKey.EntryScale = m.MapKey.EntryScale
+	w.MapKey.Unknown = decodeUnknown(m.MapKey.UnknownAttrs, m.MapKey.UnknownElements)
 
 	for _, mFeature := range m.Features.Features {
 		f := &wxx.Feature{}
@@ -295,6 +222,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			ViewLevel: mFeature.Location.ViewLevel,
 			X:         mFeature.Location.X,
 			Y:         mFeature.Location.Y,
+			Unknown:   decodeUnknown(mFeature.Location.UnknownAttrs, mFeature.Location.UnknownElements),
 		}
 
 		f.Label = &wxx.Label{
@@ -311,6 +239,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			IsProvince:  mFeature.Label.IsProvince,
 			IsGMOnly:    mFeature.Label.IsGMOnly,
 			Tags:        mFeature.Label.Tags,
+			Unknown:     decodeUnknown(mFeature.Label.UnknownAttrs, mFeature.Label.UnknownElements),
 		}
 		if f.Label.Color, err = decodeRgba(mFeature.Label.Color); err != nil {
 			return w, fmt.Errorf("feature.label.color: %w", err)
@@ -326,7 +255,10 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			X:         mFeature.Label.Location.X,
 			Y:         mFeature.Label.Location.Y,
 			Scale:     mFeature.Label.Location.Scale,
+			Unknown:   decodeUnknown(mFeature.Label.Location.UnknownAttrs, mFeature.Label.Location.UnknownElements),
 		}
+		f.Label.InnerText = mFeature.Label.InnerText
+		f.Unknown = decodeUnknown(mFeature.UnknownAttrs, mFeature.UnknownElements)
 		w.Features = append(w.Features, f)
 	}
 
@@ -362,8 +294,10 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			X:         mLabel.Location.X,
 			Y:         mLabel.Location.Y,
 			Scale:     mLabel.Location.Scale,
+			Unknown:   decodeUnknown(mLabel.Location.UnknownAttrs, mLabel.Location.UnknownElements),
 		}
 		wLabel.InnerText = mLabel.InnerText
+		wLabel.Unknown = decodeUnknown(mLabel.UnknownAttrs, mLabel.UnknownElements)
 		w.Labels = append(w.Labels, wLabel)
 	}
 
@@ -408,13 +342,15 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			StrokeWidth:           shape.StrokeWidth,
 			Tags:                  shape.Tags,
 			Type:                  shape.Type,
+			Unknown:               decodeUnknown(shape.UnknownAttrs, shape.UnknownElements),
 		}
 
 		for _, point := range shape.Points {
 			wPoint := &wxx.Point{
-				Type: point.Type,
-				X:    point.X,
-				Y:    point.Y,
+				Type:    point.Type,
+				X:       point.X,
+				Y:       point.Y,
+				Unknown: decodeUnknown(point.UnknownAttrs, point.UnknownElements),
 			}
 			wShape.Points = append(wShape.Points, wPoint)
 		}
@@ -422,9 +358,11 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 		w.Shapes = append(w.Shapes, wShape)
 	}
 
+	// the text of notes and informations is kept as written.
 	for _, note := range m.Notes.Notes {
 		wNote := &wxx.Note{
 			InnerText: note.InnerText,
+			Unknown:   decodeUnknown(note.UnknownAttrs, note.UnknownElements),
 		}
 		w.Notes = append(w.Notes, wNote)
 	}
@@ -443,6 +381,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			HolySymbol:   info.HolySymbol,
 			Domains:      info.Domains,
 			InnerText:    info.InnerText,
+			Unknown:      decodeUnknown(info.UnknownAttrs, info.UnknownElements),
 		}
 
 		for _, detail := range info.Details {
@@ -459,35 +398,75 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 				HolySymbol:   detail.HolySymbol,
 				Domains:      detail.Domains,
 				InnerText:    detail.InnerText,
+				Unknown:      decodeUnknown(detail.UnknownAttrs, detail.UnknownElements),
 			}
 			wInfo.Details = append(wInfo.Details, wDetail)
 		}
 
 		w.Informations.Informations = append(w.Informations.Informations, wInfo)
 	}
-	w.Informations.InnerText = m.Informations.InnerText
+	// the text of the container is just the whitespace around the elements.
+	w.Informations.InnerText = strings.TrimSpace(m.Informations.InnerText)
+	w.Informations.Unknown = decodeUnknown(m.Informations.UnknownAttrs, m.Informations.UnknownElements)
 
-	// convert m.Configuration to w.Configuration
+	// convert m.Configuration to w.Configuration. the text of the blocks
+	// is just the whitespace around the elements, so it's trimmed.
 	for _, mTerrainConfig := range m.Configuration.TerrainConfig {
 		wTerrainConfig := &wxx.TerrainConfig{
-			InnerText: mTerrainConfig.InnerText,
+			InnerText: strings.TrimSpace(mTerrainConfig.InnerText),
+			Unknown:   decodeUnknown(mTerrainConfig.UnknownAttrs, mTerrainConfig.UnknownElements),
+		}
+		for _, mTerrain := range mTerrainConfig.Terrains {
+			wTerrain := &wxx.TerrainType{
+				Name:    mTerrain.Name,
+				Image:   mTerrain.Image,
+				Tags:    mTerrain.Tags,
+				Unknown: decodeUnknown(mTerrain.UnknownAttrs, mTerrain.UnknownElements),
+			}
+			if wTerrain.Color, err = decodeZeroableRgba(mTerrain.Color); err != nil {
+				return w, fmt.Errorf("terrain-config: %q: color: %w", mTerrain.Name, err)
+			}
+			wTerrainConfig.Terrains = append(wTerrainConfig.Terrains, wTerrain)
 		}
 		// append the terrain configuration
 		w.Configuration.TerrainConfig = append(w.Configuration.TerrainConfig, wTerrainConfig)
 	}
 	for _, mFeatureConfig := range m.Configuration.FeatureConfig {
 		wFeatureConfig := &wxx.FeatureConfig{
-			InnerText: mFeatureConfig.InnerText,
+			InnerText: strings.TrimSpace(mFeatureConfig.InnerText),
+			Unknown:   decodeUnknown(mFeatureConfig.UnknownAttrs, mFeatureConfig.UnknownElements),
+		}
+		for _, mFeature := range mFeatureConfig.Features {
+			wFeature := &wxx.FeatureType{
+				Name:    mFeature.Name,
+				Image:   mFeature.Image,
+				Tags:    mFeature.Tags,
+				Unknown: decodeUnknown(mFeature.UnknownAttrs, mFeature.UnknownElements),
+			}
+			if wFeature.Color, err = decodeZeroableRgba(mFeature.Color); err != nil {
+				return w, fmt.Errorf("feature-config: %q: color: %w", mFeature.Name, err)
+			}
+			wFeatureConfig.Features = append(wFeatureConfig.Features, wFeature)
 		}
 		w.Configuration.FeatureConfig = append(w.Configuration.FeatureConfig, wFeatureConfig)
 	}
 	for _, mTextureConfig := range m.Configuration.TextureConfig {
 		wTextureConfig := &wxx.TextureConfig{
-			InnerText: mTextureConfig.InnerText,
+			InnerText: strings.TrimSpace(mTextureConfig.InnerText),
+			Unknown:   decodeUnknown(mTextureConfig.UnknownAttrs, mTextureConfig.UnknownElements),
+		}
+		for _, mTexture := range mTextureConfig.Textures {
+			wTextureConfig.Textures = append(wTextureConfig.Textures, &wxx.TextureType{
+				Name:    mTexture.Name,
+				Image:   mTexture.Image,
+				Tags:    mTexture.Tags,
+				Unknown: decodeUnknown(mTexture.UnknownAttrs, mTexture.UnknownElements),
+			})
 		}
 		w.Configuration.TextureConfig = append(w.Configuration.TextureConfig, wTextureConfig)
 	}
 	for _, mTextConfig := range m.Configuration.TextConfig {
+		w.Configuration.TextConfig.Unknown = mergeUnknown(w.Configuration.TextConfig.Unknown, decodeUnknown(mTextConfig.UnknownAttrs, mTextConfig.UnknownElements), len(w.Configuration.TextConfig.LabelStyles))
 		for _, mLabelStyle := range mTextConfig.LabelStyles {
 			wLabelStyle := &wxx.LabelStyle{
 				Name:        mLabelStyle.Name,
@@ -496,6 +475,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 				IsBold:      mLabelStyle.IsBold,
 				IsItalic:    mLabelStyle.IsItalic,
 				OutlineSize: mLabelStyle.OutlineSize,
+				Unknown:     decodeUnknown(mLabelStyle.UnknownAttrs, mLabelStyle.UnknownElements),
 			}
 			if wLabelStyle.Color, err = decodeRgba(mLabelStyle.Color); err != nil {
 				return w, fmt.Errorf("labelStyle.color: %w", err)
@@ -512,6 +492,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 		}
 	}
 	for _, mShapeConfig := range m.Configuration.ShapeConfig {
+		w.Configuration.ShapeConfig.Unknown = mergeUnknown(w.Configuration.ShapeConfig.Unknown, decodeUnknown(mShapeConfig.UnknownAttrs, mShapeConfig.UnknownElements), len(w.Configuration.ShapeConfig.ShapeStyles))
 		for _, mShapeStyle := range mShapeConfig.ShapeStyles {
 			wShapeStyle := &wxx.ShapeStyle{
 				Name:          mShapeStyle.Name,
@@ -537,6 +518,7 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 				BbIterations:  mShapeStyle.BbIterations,
 				FillTexture:   mShapeStyle.FillTexture,
 				StrokeTexture: mShapeStyle.StrokeTexture,
+				Unknown:       decodeUnknown(mShapeStyle.UnknownAttrs, mShapeStyle.UnknownElements),
 			}
 			if wShapeStyle.StrokePaint, err = decodeRgba(mShapeStyle.StrokePaint); err != nil {
 				return w, fmt.Errorf("shapeStyle.strokePaint: %w", err)
@@ -553,6 +535,121 @@ func wxmlV173ToWXX(m *wxml173.Map) (*wxx.Map, error) {
 			w.Configuration.ShapeConfig.ShapeStyles = append(w.Configuration.ShapeConfig.ShapeStyles, wShapeStyle)
 		}
 	}
+	w.Configuration.Unknown = decodeUnknown(m.Configuration.UnknownAttrs, m.Configuration.UnknownElements)
+
+	w.Unknown = decodeUnknown(m.UnknownAttrs, m.UnknownElements)
+	w.UnknownFeatures = decodeUnknown(m.Features.UnknownAttrs, m.Features.UnknownElements)
+	w.UnknownLabels = decodeUnknown(m.Labels.UnknownAttrs, m.Labels.UnknownElements)
+	w.UnknownShapes = decodeUnknown(m.Shapes.UnknownAttrs, m.Shapes.UnknownElements)
+	w.UnknownNotes = decodeUnknown(m.Notes.UnknownAttrs, m.Notes.UnknownElements)
 
 	return w, nil
 }
+
+// decodeTile converts a line from a tilerow to a tile.
+// The values are tab delimited: TerrainMapIndex Elevation IsIcy IsGMOnly
+// Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
+func decodeTile(line string) (*wxx.Tile, error) {
+	t := &wxx.Tile{}
+	var err error
+	values := strings.Split(line, "\t")
+	switch len(values) {
+	case 6, 7, 11, 12: // allowed
+	default:
+		return nil, fmt.Errorf("values: expected 6/7/11/12, got %d", len(values))
+	}
+	if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
+		return nil, fmt.Errorf("value: terrainType: %w", err)
+	}
+	if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
+		return nil, fmt.Errorf("value: elevation: %w", err)
+	}
+	t.IsIcy = values[2] == "1"
+	t.IsGMOnly = values[3] == "1"
+	if t.Resources.Animal, err = strconv.Atoi(values[4]); err != nil {
+		return nil, fmt.Errorf("value: animals: %w", err)
+	} else if t.Resources.Animal < 0 {
+		return nil, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
+	} else if t.Resources.Animal > 100 {
+		return nil, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
+	}
+	if len(values) == 6 || len(values) == 7 {
+		if values[5] != "Z" {
+			return nil, fmt.Errorf("value: sentinel: %w", fmt.Errorf("invalid value"))
+		}
+	} else {
+		if t.Resources.Brick, err = strconv.Atoi(values[5]); err != nil {
+			return nil, fmt.Errorf("value: brick: %q: %w", values, err)
+		} else if t.Resources.Brick < 0 {
+			return nil, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Brick > 100 {
+			return nil, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
+		}
+		if t.Resources.Crops, err = strconv.Atoi(values[6]); err != nil {
+			return nil, fmt.Errorf("value: crops: %w", err)
+		} else if t.Resources.Crops < 0 {
+			return nil, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Crops > 100 {
+			return nil, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
+		}
+		if t.Resources.Gems, err = strconv.Atoi(values[7]); err != nil {
+			return nil, fmt.Errorf("value: gems: %w", err)
+		} else if t.Resources.Gems < 0 {
+			return nil, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Gems > 100 {
+			return nil, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
+		}
+		if t.Resources.Lumber, err = strconv.Atoi(values[8]); err != nil {
+			return nil, fmt.Errorf("value: lumber: %w", err)
+		} else if t.Resources.Lumber < 0 {
+			return nil, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Lumber > 100 {
+			return nil, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
+		}
+		if t.Resources.Metals, err = strconv.Atoi(values[9]); err != nil {
+			return nil, fmt.Errorf("value: metals: %w", err)
+		} else if t.Resources.Metals < 0 {
+			return nil, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Metals > 100 {
+			return nil, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
+		}
+		if t.Resources.Rock, err = strconv.Atoi(values[10]); err != nil {
+			return nil, fmt.Errorf("value: rock: %w", err)
+		} else if t.Resources.Rock < 0 {
+			return nil, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
+		} else if t.Resources.Rock > 100 {
+			return nil, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
+		}
+	}
+	if len(values) == 7 || len(values) == 12 {
+		// split rgba
+		if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
+			return nil, fmt.Errorf("value: rgba: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+// decodeTerrainMap converts the terrainmap to a list of terrains.
+// The labels and indexes are stored as tab delimited columns.
+func decodeTerrainMap(s string) ([]*wxx.Terrain, error) {
+	fields := strings.Split(s, "\t")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("expected even number of fields, got odd")
+	}
+	var list []*wxx.Terrain
+	for len(fields) != 0 {
+		t := &wxx.Terrain{
+			Label: fields[0],
+		}
+		var err error
+		t.Index, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("field: %s: invalid index: %w", fields[0], err)
+		}
+		list = append(list, t)
+		fields = fields[2:]
+	}
+	return list, nil
+}
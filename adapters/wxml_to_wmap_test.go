@@ -0,0 +1,37 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// TestWXMLToWXXNoTiles checks that a map without any tiles still
+// has the rest of its elements, such as the map key, translated.
+func TestWXMLToWXXNoTiles(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "maps", "basic.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte(`tilesWide="2" tilesHigh="2"`), []byte(`tilesWide="0" tilesHigh="0"`), 1)
+	data = regexp.MustCompile(`(?s)<tilerow>.*?</tilerow>\s*`).ReplaceAll(data, nil)
+
+	wxml, err := UTF8ToWXML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Width() != 0 || m.Height() != 0 {
+		t.Errorf("tiles: got %dx%d, want 0x0", m.Width(), m.Height())
+	}
+	if m.MapKey.TitleText != "Map Key" || m.MapKey.Viewlevel != "WORLD" || m.MapKey.BackgroundColor == nil {
+		t.Errorf("mapkey: got %+v, want the map key from the file", m.MapKey)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+)
+
+// WXXToUTF8 writes the map to w as UTF-8 XML, including the XML header,
+// using the codec registered for the version.
+func WXXToUTF8(w io.Writer, m *wxx.Map, version string) error {
+	codec, err := LookupEncoder(version)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, xmlHeader); err != nil {
+		return err
+	}
+	return codec.Encode(w, m, version)
+}
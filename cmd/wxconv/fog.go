@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"github.com/mdhender/wxconv/hexgrid"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fogCommand creates a map for each player that shows only the hexes
+// that the player has seen.
+func fogCommand(args []string) int {
+	fs := flag.NewFlagSet("fog", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv fog [flags] -visibility seen.csv master.wxx\n")
+		fs.PrintDefaults()
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var visibilityFile string
+	fs.StringVar(&visibilityFile, "visibility", visibilityFile, ".csv or .json file listing the hexes each player has seen")
+
+	var outputPath string
+	fs.StringVar(&outputPath, "output", ".", "directory to create the player maps in")
+
+	var fogTerrain string
+	fs.StringVar(&fogTerrain, "fog-terrain", wxconv.DefaultUnknownTerrain, "terrain for hexes that haven't been seen")
+
+	var targetVersion string
+	fs.StringVar(&targetVersion, "target-version", targetVersion, fmt.Sprintf("version of .wxx file to create (%s)", strings.Join(writableVersions(), ", ")))
+
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || visibilityFile == "" {
+		fs.Usage()
+		return 2
+	}
+	masterFile := fs.Arg(0)
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(visibilityFile)), ".")
+	if format != "csv" && format != "json" {
+		log.Printf("fog: %s: expected a .csv or .json file\n", visibilityFile)
+		return 2
+	}
+	if sb, err := os.Stat(outputPath); err != nil {
+		log.Printf("fog: %v\n", err)
+		return 1
+	} else if !sb.IsDir() {
+		log.Printf("fog: %s: is not a directory\n", outputPath)
+		return 1
+	}
+
+	opts := &wxconv.Options{
+		Logger:        newLogger(debug),
+		TargetVersion: targetVersion,
+	}
+
+	m, err := wxconv.ImportWXXFile(masterFile, opts)
+	if err != nil {
+		log.Printf("fog: %v\n", err)
+		return 1
+	}
+	numbering, err := hexgrid.NewNumbering(m)
+	if err != nil {
+		log.Printf("fog: %s: %v\n", masterFile, err)
+		return 1
+	}
+
+	fd, err := os.Open(visibilityFile)
+	if err != nil {
+		log.Printf("fog: %v\n", err)
+		return 1
+	}
+	visibility, err := wxconv.ReadVisibility(fd, format, numbering)
+	_ = fd.Close() // ignore errors
+	if err != nil {
+		log.Printf("fog: %s: %v\n", visibilityFile, err)
+		return 1
+	}
+
+	var players []string
+	for player := range visibility {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+
+	exitCode := 0
+	for _, player := range players {
+		if player == "." || player == ".." || strings.ContainsAny(player, `/\`) {
+			log.Printf("fog: player %q: can't be used as a file name\n", player)
+			exitCode = 1
+			continue
+		}
+		pm, err := wxconv.FogOfWar(m, visibility[player], fogTerrain)
+		if err != nil {
+			log.Printf("fog: player %q: %v\n", player, err)
+			exitCode = 1
+			continue
+		}
+		path := filepath.Join(outputPath, player+".wxx")
+		if err = wxconv.ExportWXXFile(pm, path, opts); err != nil {
+			log.Printf("fog: player %q: %v\n", player, err)
+			exitCode = 1
+			continue
+		}
+		log.Printf("created %s\n", path)
+	}
+
+	return exitCode
+}
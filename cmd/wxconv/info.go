@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+// infoCommand prints a summary of what a .wxx file contains.
+func infoCommand(args []string) int {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv info [flags] file.wxx\n")
+		fs.PrintDefaults()
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var asJSON bool
+	fs.BoolVar(&asJSON, "json", asJSON, "print the summary as JSON")
+
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	m, err := wxconv.ImportWXXFile(fs.Arg(0), &wxconv.Options{Logger: newLogger(debug)})
+	if err != nil {
+		log.Printf("info: %v\n", err)
+		return 1
+	}
+	s := wxconv.Summarize(m)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(s); err != nil {
+			log.Printf("info: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	printSummary(tw, s)
+	if err = tw.Flush(); err != nil {
+		log.Printf("info: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printSummary(w io.Writer, s *wxconv.Summary) {
+	fmt.Fprintf(w, "version\t%s\n", s.Version)
+	fmt.Fprintf(w, "type\t%s\n", s.Type)
+	fmt.Fprintf(w, "size\t%dx%d\n", s.Width, s.Height)
+	fmt.Fprintf(w, "orientation\t%s\n", s.Orientation)
+	fmt.Fprintf(w, "view level\t%s\n", s.ViewLevel)
+	printCounts(w, "terrain", s.Terrain)
+	printCounts(w, "features", s.Features)
+	fmt.Fprintf(w, "labels\t%d\n", s.Labels)
+	fmt.Fprintf(w, "shapes\t%d\n", s.Shapes)
+	fmt.Fprintf(w, "notes\t%d\n", s.Notes)
+	printCounts(w, "informations", s.Informations)
+	fmt.Fprintf(w, "layers\t%d\n", len(s.Layers))
+	for _, l := range s.Layers {
+		visibility := "visible"
+		if !l.IsVisible {
+			visibility = "hidden"
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", l.Name, visibility)
+	}
+	fmt.Fprintf(w, "gm-only\t\n")
+	fmt.Fprintf(w, "  tiles\t%d\n", s.GMOnly.Tiles)
+	fmt.Fprintf(w, "  features\t%d\n", s.GMOnly.Features)
+	fmt.Fprintf(w, "  feature labels\t%d\n", s.GMOnly.FeatureLabels)
+	fmt.Fprintf(w, "  labels\t%d\n", s.GMOnly.Labels)
+	fmt.Fprintf(w, "  shapes\t%d\n", s.GMOnly.Shapes)
+	fmt.Fprintf(w, "  notes\t%d\n", s.GMOnly.Notes)
+	fmt.Fprintf(w, "  informations\t%d\n", s.GMOnly.Informations)
+	fmt.Fprintf(w, "resources\t\n")
+	fmt.Fprintf(w, "  animal\t%d\n", s.Resources.Animal)
+	fmt.Fprintf(w, "  brick\t%d\n", s.Resources.Brick)
+	fmt.Fprintf(w, "  crops\t%d\n", s.Resources.Crops)
+	fmt.Fprintf(w, "  gems\t%d\n", s.Resources.Gems)
+	fmt.Fprintf(w, "  lumber\t%d\n", s.Resources.Lumber)
+	fmt.Fprintf(w, "  metals\t%d\n", s.Resources.Metals)
+	fmt.Fprintf(w, "  rock\t%d\n", s.Resources.Rock)
+}
+
+func printCounts(w io.Writer, name string, counts []wxconv.Count) {
+	total := 0
+	for _, c := range counts {
+		total += c.Count
+	}
+	fmt.Fprintf(w, "%s\t%d\n", name, total)
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %s\t%d\n", c.Name, c.Count)
+	}
+}
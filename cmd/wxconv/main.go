@@ -4,14 +4,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/mdhender/semver"
 	"github.com/mdhender/wxconv"
+	"github.com/mdhender/wxconv/adapters"
 	"github.com/mdhender/wxconv/models/wxx"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 var (
@@ -23,9 +29,26 @@ var (
 	}
 )
 
+// commands are the sub-commands. If the first argument isn't one of
+// them, the arguments are treated as flags for the import/export mode.
+var commands = map[string]func(args []string) int{
+	"fog":      fogCommand,
+	"info":     infoCommand,
+	"render":   renderCommand,
+	"show":     showCommand,
+	"validate": validateCommand,
+	"verify":   verifyCommand,
+}
+
 func main() {
 	log.SetFlags(log.Ltime)
 
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
 	var debug bool
 	flag.BoolVar(&debug, "debug", debug, "show debug output")
 
@@ -41,9 +64,24 @@ func main() {
 	var exportWXXFile string
 	flag.StringVar(&exportWXXFile, "export", exportWXXFile, ".wxx file to create")
 
+	var targetVersion string
+	flag.StringVar(&targetVersion, "target-version", targetVersion, fmt.Sprintf("version of .wxx file to create (%s)", strings.Join(writableVersions(), ", ")))
+
 	var exportJSONFile string
 	flag.StringVar(&exportJSONFile, "export-json", exportJSONFile, ".json file to create")
 
+	var importTilesCSVFile string
+	flag.StringVar(&importTilesCSVFile, "import-tiles-csv", importTilesCSVFile, ".csv file of tiles to apply to the imported map")
+
+	var exportTilesCSVFile string
+	flag.StringVar(&exportTilesCSVFile, "export-tiles-csv", exportTilesCSVFile, ".csv file of tiles to create")
+
+	var stripGM bool
+	flag.BoolVar(&stripGM, "strip-gm", stripGM, "remove GM-only content from the exported files")
+
+	var unknownTerrain string
+	flag.StringVar(&unknownTerrain, "unknown-terrain", wxconv.DefaultUnknownTerrain, "terrain for GM-only tiles with -strip-gm")
+
 	var debugOutputPath string
 	flag.StringVar(&debugOutputPath, "debug-output-path", debugOutputPath, "path to create debug files in")
 
@@ -61,7 +99,14 @@ func main() {
 	hasJSONImport, hasJSONExport := importJSONFile != "", exportJSONFile != ""
 	hasWXXImport, hasWXXExport := importWXXFile != "", exportWXXFile != ""
 
+	if targetVersion != "" {
+		if _, err := adapters.LookupEncoder(targetVersion); err != nil {
+			log.Fatalf("error: target-version: %v\n", err)
+		}
+	}
+
 	if hasJSONImport && hasWXXImport {
+		log.Fatalf("error: you must not specify both -import and -import-json\n")
 	} else if hasJSONImport {
 		// input must exist and be a regular file
 		sb, err := os.Stat(importJSONFile)
@@ -100,13 +145,36 @@ func main() {
 		}
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := &wxconv.Options{
+		Context:        ctx,
+		Logger:         newLogger(debug),
+		TargetVersion:  targetVersion,
+		StripGM:        stripGM,
+		UnknownTerrain: unknownTerrain,
+	}
+	if debug {
+		opts.Timing = func(stage string, elapsed time.Duration) {
+			opts.Logger.Debug("timing", "stage", stage, "elapsed", elapsed)
+		}
+	}
+	if debugOutputPath != "" {
+		opts.DebugSink = wxconv.DebugDirectory(debugOutputPath)
+	}
+
 	var m *wxx.Map
 	var err error
 
 	if hasJSONImport {
-		log.Fatalf("error: json import not implemented yet\n")
+		m, err = wxconv.ImportJSONFile(importJSONFile, opts)
+		if err != nil {
+			log.Printf("import: %s\n", importJSONFile)
+			log.Fatalf("import: %v", err)
+		}
 	} else if hasWXXImport {
-		m, err = wxconv.ImportWXXFile(importWXXFile, debug, debugOutputPath)
+		m, err = wxconv.ImportWXXFile(importWXXFile, opts)
 		if err != nil {
 			log.Printf("import: %s\n", importWXXFile)
 			log.Fatalf("import: %v", err)
@@ -115,16 +183,36 @@ func main() {
 		log.Fatalf("error: you must specify a file to import\n")
 	}
 
+	if importTilesCSVFile != "" {
+		if err = importTilesCSV(m, importTilesCSVFile); err != nil {
+			log.Fatalf("import: %s: %v", importTilesCSVFile, err)
+		}
+		log.Printf("applied %s\n", importTilesCSVFile)
+	}
+
+	if exportTilesCSVFile != "" {
+		tiles := m
+		if stripGM {
+			if tiles, err = wxconv.StripGMOnly(m, opts.UnknownTerrain); err != nil {
+				log.Fatalf("export: %s: %v", exportTilesCSVFile, err)
+			}
+		}
+		if err = exportTilesCSV(tiles, exportTilesCSVFile); err != nil {
+			log.Fatalf("export: %s: %v", exportTilesCSVFile, err)
+		}
+		log.Printf("created %s\n", exportTilesCSVFile)
+	}
+
 	if hasJSONExport {
-		if err = wxconv.ExportJSONFile(m, exportJSONFile, true, debugOutputPath); err != nil {
-			log.Printf("export: %s", exportWXXFile)
+		if err = wxconv.ExportJSONFile(m, exportJSONFile, opts); err != nil {
+			log.Printf("export: %s", exportJSONFile)
 			log.Fatalf("export: %v", err)
 		}
 		log.Printf("created %s\n", exportJSONFile)
 	}
 
 	if hasWXXExport {
-		err := wxconv.ExportWXXFile(m, exportWXXFile, debug, debugOutputPath)
+		err := wxconv.ExportWXXFile(m, exportWXXFile, opts)
 		if err != nil {
 			log.Printf("export: %s", exportWXXFile)
 			log.Fatalf("export: %v", err)
@@ -132,3 +220,49 @@ func main() {
 		log.Printf("created %s\n", exportWXXFile)
 	}
 }
+
+// importTilesCSV applies the tiles in the CSV file to the map.
+func importTilesCSV(m *wxx.Map, path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(fd *os.File) {
+		_ = fd.Close() // ignore errors
+	}(fd)
+	return wxconv.ImportTilesCSV(fd, m)
+}
+
+// exportTilesCSV writes the tiles of the map to a CSV file.
+func exportTilesCSV(m *wxx.Map, path string) error {
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = wxconv.ExportTilesCSV(fd, m)
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writableVersions returns the versions that we can export.
+func writableVersions() []string {
+	var versions []string
+	for _, codec := range adapters.Codecs() {
+		if codec.Capabilities().Write {
+			versions = append(versions, codec.Version)
+		}
+	}
+	return versions
+}
+
+// newLogger returns a logger that writes to stderr.
+// Debug messages are only written if debug is true.
+func newLogger(debug bool) *slog.Logger {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
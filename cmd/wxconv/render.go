@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"github.com/mdhender/wxconv/render"
+	"github.com/mdhender/wxconv/render/raster"
+	"github.com/mdhender/wxconv/render/svg"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// renderCommand draws a map as an image.
+func renderCommand(args []string) int {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv render [flags] file.wxx\n")
+		fs.PrintDefaults()
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var format string
+	fs.StringVar(&format, "format", "svg", "image format (svg, png)")
+
+	var outputFile string
+	fs.StringVar(&outputFile, "output", outputFile, "image file to create (default is the input with the format's extension)")
+
+	var paletteFile string
+	fs.StringVar(&paletteFile, "palette", paletteFile, ".json file mapping terrain names to colors")
+
+	var scale float64
+	fs.Float64Var(&scale, "scale", 1, "size of the image relative to the map (svg)")
+
+	var width int
+	fs.IntVar(&width, "width", width, "width of the image in pixels (png, default is the size of the map)")
+
+	var gridNumbers bool
+	fs.BoolVar(&gridNumbers, "grid-numbers", gridNumbers, "draw the grid numbers even if the map hides them")
+
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	inputFile := fs.Arg(0)
+	if format != "svg" && format != "png" {
+		log.Printf("render: format %q: not supported\n", format)
+		return 2
+	}
+	if outputFile == "" {
+		outputFile = strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + "." + format
+	}
+
+	var palette render.Palette
+	if paletteFile != "" {
+		fd, err := os.Open(paletteFile)
+		if err != nil {
+			log.Printf("render: %v\n", err)
+			return 1
+		}
+		palette, err = render.ReadPalette(fd)
+		_ = fd.Close() // ignore errors
+		if err != nil {
+			log.Printf("render: %s: %v\n", paletteFile, err)
+			return 1
+		}
+	}
+
+	m, err := wxconv.ImportWXXFile(inputFile, &wxconv.Options{Logger: newLogger(debug)})
+	if err != nil {
+		log.Printf("render: %v\n", err)
+		return 1
+	}
+
+	if gridNumbers {
+		m.ShowGrid, m.ShowGridNumbers = true, true
+	}
+
+	if err = renderFile(outputFile, func(fd *os.File) error {
+		if format == "png" {
+			return raster.Render(fd, m, &raster.Options{Palette: palette, Width: width})
+		}
+		return svg.Render(fd, m, &svg.Options{Palette: palette, Scale: scale})
+	}); err != nil {
+		log.Printf("render: %s: %v\n", outputFile, err)
+		return 1
+	}
+	log.Printf("created %s\n", outputFile)
+
+	return 0
+}
+
+// renderFile creates the file and calls draw to write the image.
+// The file is removed if draw fails.
+func renderFile(path string, draw func(fd *os.File) error) error {
+	fd, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = draw(fd)
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path) // don't leave a partial file behind
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"github.com/mdhender/wxconv/render"
+	"github.com/mdhender/wxconv/render/ascii"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// showCommand prints the tiles of a map in the terminal.
+func showCommand(args []string) int {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv show [flags] file.wxx\n")
+		fs.PrintDefaults()
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var at string
+	fs.StringVar(&at, "at", "0,0", "column and row of the tile in the top-left corner")
+
+	var size string
+	fs.StringVar(&size, "size", size, "columns and rows of tiles to show, e.g. 40x20 (default is the whole map)")
+
+	var useColor bool
+	fs.BoolVar(&useColor, "color", useColor, "draw the tiles in color using ANSI escape codes")
+
+	var glyphsFile string
+	fs.StringVar(&glyphsFile, "glyphs", glyphsFile, ".json file mapping terrain names to characters")
+
+	var paletteFile string
+	fs.StringVar(&paletteFile, "palette", paletteFile, ".json file mapping terrain names to colors")
+
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := &ascii.Options{Color: useColor}
+	var err error
+	if opts.At.Col, opts.At.Row, err = parsePair(at, ","); err != nil {
+		log.Printf("show: -at %q: expected col,row\n", at)
+		return 2
+	}
+	if size != "" {
+		if opts.Cols, opts.Rows, err = parsePair(size, "x"); err != nil || opts.Cols <= 0 || opts.Rows <= 0 {
+			log.Printf("show: -size %q: expected colsxrows\n", size)
+			return 2
+		}
+	}
+	if glyphsFile != "" {
+		fd, err := os.Open(glyphsFile)
+		if err != nil {
+			log.Printf("show: %v\n", err)
+			return 1
+		}
+		opts.Glyphs, err = ascii.ReadGlyphs(fd)
+		_ = fd.Close() // ignore errors
+		if err != nil {
+			log.Printf("show: %s: %v\n", glyphsFile, err)
+			return 1
+		}
+	}
+	if paletteFile != "" {
+		fd, err := os.Open(paletteFile)
+		if err != nil {
+			log.Printf("show: %v\n", err)
+			return 1
+		}
+		opts.Palette, err = render.ReadPalette(fd)
+		_ = fd.Close() // ignore errors
+		if err != nil {
+			log.Printf("show: %s: %v\n", paletteFile, err)
+			return 1
+		}
+	}
+
+	m, err := wxconv.ImportWXXFile(fs.Arg(0), &wxconv.Options{Logger: newLogger(debug)})
+	if err != nil {
+		log.Printf("show: %v\n", err)
+		return 1
+	}
+	if err = ascii.Render(os.Stdout, m, opts); err != nil {
+		log.Printf("show: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parsePair parses two integers separated by sep, e.g. "3,4".
+// Anything else in the value is an error.
+func parsePair(s, sep string) (int, int, error) {
+	a, b, ok := strings.Cut(s, sep)
+	if !ok {
+		return 0, 0, fmt.Errorf("%q: missing %q", s, sep)
+	}
+	x, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := strconv.Atoi(b)
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"github.com/mdhender/wxconv/validate"
+	"log"
+)
+
+// validateCommand checks .wxx files for problems that Worldographer would
+// reject. It returns 1 if any file has errors so that it can be used to
+// gate a repository of maps.
+func validateCommand(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv validate [flags] file.wxx...\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nrules:\n")
+		for _, rule := range validate.Rules {
+			fmt.Fprintf(fs.Output(), "  %-12s %s\n", rule.Name, rule.Description)
+		}
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var fix bool
+	fs.BoolVar(&fix, "fix", fix, "apply the automatic fixes and write the map to -output")
+
+	var outputFile string
+	fs.StringVar(&outputFile, "output", outputFile, ".wxx file to create with the fixed map")
+
+	var showFixes bool
+	fs.BoolVar(&showFixes, "suggest", showFixes, "show how to fix each finding")
+
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	} else if fix && (outputFile == "" || fs.NArg() != 1) {
+		log.Printf("validate: -fix needs -output and a single input file\n")
+		return 2
+	}
+
+	opts := &wxconv.Options{Logger: newLogger(debug)}
+
+	exitCode := 0
+	for _, path := range fs.Args() {
+		m, err := wxconv.ImportWXXFile(path, opts)
+		if err != nil {
+			log.Printf("validate: %v\n", err)
+			exitCode = 1
+			continue
+		}
+
+		findings := validate.Validate(m)
+		if fix {
+			fixed, err := validate.Fix(m, findings)
+			if err != nil {
+				log.Printf("validate: %s: fix: %v\n", path, err)
+				return 1
+			}
+			log.Printf("validate: %s: fixed %d of %d findings\n", path, fixed, len(findings))
+			findings = validate.Validate(m)
+		}
+
+		for _, f := range findings {
+			fmt.Printf("%s: %s\n", path, f)
+			if showFixes {
+				suggestion := f.Fix
+				if f.CanFix() {
+					suggestion += " (can be fixed with -fix)"
+				}
+				fmt.Printf("%s:     fix: %s\n", path, suggestion)
+			}
+		}
+		if validate.HasErrors(findings) {
+			exitCode = 1
+		} else {
+			log.Printf("validate: %s: ok (%d warnings)\n", path, len(findings))
+		}
+
+		if fix {
+			if err = wxconv.ExportWXXFile(m, outputFile, opts); err != nil {
+				log.Printf("validate: %v\n", err)
+				return 1
+			}
+			log.Printf("validate: created %s\n", outputFile)
+		}
+	}
+
+	return exitCode
+}
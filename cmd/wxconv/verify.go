@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mdhender/wxconv"
+	"log"
+	"os"
+	"strings"
+)
+
+// verifyCommand imports a .wxx file, exports it in memory, re-imports it,
+// and reports any differences. It returns 1 if there are differences so
+// that it can be used to gate a repository of maps.
+func verifyCommand(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: wxconv verify [flags] file.wxx...\n")
+		fs.PrintDefaults()
+	}
+
+	var debug bool
+	fs.BoolVar(&debug, "debug", debug, "show debug output")
+
+	var compareXML bool
+	fs.BoolVar(&compareXML, "xml", compareXML, "also compare the canonical XML of the input and output")
+
+	var targetVersion string
+	fs.StringVar(&targetVersion, "target-version", targetVersion, fmt.Sprintf("version of .wxx file to create (%s)", strings.Join(writableVersions(), ", ")))
+
+	var maxDiffs int
+	fs.IntVar(&maxDiffs, "max-diffs", 25, "maximum number of differences to report per file (0 for all)")
+
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := &wxconv.Options{
+		Logger:        newLogger(debug),
+		TargetVersion: targetVersion,
+	}
+
+	exitCode := 0
+	for _, path := range fs.Args() {
+		report, err := verifyFile(path, compareXML, opts)
+		if err != nil {
+			log.Printf("verify: %s: %v\n", path, err)
+			exitCode = 1
+			continue
+		}
+		if report.OK() {
+			log.Printf("verify: %s: ok (%s)\n", path, report.Version)
+			continue
+		}
+		exitCode = 1
+		log.Printf("verify: %s: %d differences, %d xml differences (%s)\n", path, len(report.Differences), len(report.XMLDifferences), report.Version)
+		printDifferences(path, "map", report.Differences, maxDiffs)
+		printDifferences(path, "xml", report.XMLDifferences, maxDiffs)
+	}
+
+	return exitCode
+}
+
+func verifyFile(path string, compareXML bool, opts *wxconv.Options) (*wxconv.VerifyReport, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(fd *os.File) {
+		_ = fd.Close() // ignore errors
+	}(fd)
+	return wxconv.Verify(fd, compareXML, opts)
+}
+
+func printDifferences(path, kind string, diffs []wxconv.Difference, maxDiffs int) {
+	for n, diff := range diffs {
+		if maxDiffs > 0 && n == maxDiffs {
+			fmt.Printf("%s: %s: ... %d more\n", path, kind, len(diffs)-n)
+			break
+		}
+		fmt.Printf("%s: %s: %s\n", path, kind, diff)
+	}
+}
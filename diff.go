@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"reflect"
+	"sort"
+)
+
+// Difference is a value that isn't the same in two maps.
+type Difference struct {
+	Path string // path to the value, e.g. "Tiles.TileRows[3][7].Terrain"
+	A    string // value in the first map
+	B    string // value in the second map
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Path, d.A, d.B)
+}
+
+// Diff returns the differences between two maps.
+// The meta-data is ignored since it records when the map was loaded.
+func Diff(a, b *wxx.Map) []Difference {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []Difference{{Path: "map", A: describe(reflect.ValueOf(a)), B: describe(reflect.ValueOf(b))}}
+	}
+	ca, cb := *a, *b
+	ca.MetaData, cb.MetaData = a.MetaData, a.MetaData
+	var diffs []Difference
+	diffValues("", reflect.ValueOf(ca), reflect.ValueOf(cb), &diffs)
+	return diffs
+}
+
+// diffValues walks both values and appends any differences to diffs.
+func diffValues(path string, a, b reflect.Value, diffs *[]Difference) {
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*diffs = append(*diffs, Difference{Path: path, A: describe(a), B: describe(b)})
+			}
+			return
+		}
+		diffValues(path, a.Elem(), b.Elem(), diffs)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			diffValues(joinPath(path, field.Name), a.Field(i), b.Field(i), diffs)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			*diffs = append(*diffs, Difference{Path: path + ".length", A: fmt.Sprint(a.Len()), B: fmt.Sprint(b.Len())})
+		}
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), diffs)
+		}
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		var names []string
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			k := keys[name]
+			diffValues(fmt.Sprintf("%s[%s]", path, name), a.MapIndex(k), b.MapIndex(k), diffs)
+		}
+	case reflect.Invalid:
+		// a key that is missing from the first map
+		if b.IsValid() {
+			*diffs = append(*diffs, Difference{Path: path, A: "<missing>", B: describe(b)})
+		}
+	default:
+		if !b.IsValid() {
+			*diffs = append(*diffs, Difference{Path: path, A: describe(a), B: "<missing>"})
+		} else if a.Interface() != b.Interface() {
+			*diffs = append(*diffs, Difference{Path: path, A: describe(a), B: describe(b)})
+		}
+	}
+}
+
+// describe returns a short representation of the value for a report.
+func describe(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return "<missing>"
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Pointer, reflect.Struct, reflect.Slice, reflect.Map:
+		return fmt.Sprintf("%s{...}", v.Type())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
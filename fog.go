@@ -0,0 +1,235 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Visibility is the list of hexes that each player has seen,
+// keyed by the player's name.
+type Visibility map[string][]hexgrid.Hex
+
+// visibilityRecord is a hex that a player has seen. The hex is either
+// a grid label ("04.12") or a column and row.
+type visibilityRecord struct {
+	Player string `json:"player"`
+	Hex    string `json:"hex,omitempty"`
+	Col    *int   `json:"col,omitempty"`
+	Row    *int   `json:"row,omitempty"`
+}
+
+// ReadVisibility reads the hexes that each player has seen.
+//
+// The format is "csv" or "json". A CSV file must have a header with a
+// "player" column and either a "hex" column or "col" and "row" columns.
+// A JSON file is a list of objects with the same fields:
+//
+//	[{"player": "alice", "hex": "04.12"}, {"player": "bob", "col": 3, "row": 11}]
+//
+// Hex labels are parsed with the map's grid numbering.
+func ReadVisibility(r io.Reader, format string, n *hexgrid.Numbering) (Visibility, error) {
+	var records []visibilityRecord
+	switch format {
+	case "csv":
+		var err error
+		if records, err = readVisibilityCSV(r); err != nil {
+			return nil, fmt.Errorf("visibility: %w", err)
+		}
+	case "json":
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("visibility: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("visibility: %q: unknown format", format)
+	}
+
+	v := Visibility{}
+	for i, record := range records {
+		if record.Player == "" {
+			return nil, fmt.Errorf("visibility: record %d: missing player", i+1)
+		}
+		var h hexgrid.Hex
+		if record.Hex != "" {
+			var err error
+			if h, err = n.Parse(record.Hex); err != nil {
+				return nil, fmt.Errorf("visibility: record %d: %w", i+1, err)
+			}
+		} else if record.Col != nil && record.Row != nil {
+			h = hexgrid.Hex{Col: *record.Col, Row: *record.Row}
+		} else {
+			return nil, fmt.Errorf("visibility: record %d: missing hex", i+1)
+		}
+		v[record.Player] = append(v[record.Player], h)
+	}
+	return v, nil
+}
+
+// readVisibilityCSV returns the records from a CSV file.
+func readVisibilityCSV(r io.Reader) ([]visibilityRecord, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	player, hasPlayer := columns["player"]
+	hex, hasHex := columns["hex"]
+	col, hasCol := columns["col"]
+	row, hasRow := columns["row"]
+	if !hasPlayer {
+		return nil, fmt.Errorf("header: missing player column")
+	} else if !hasHex && !(hasCol && hasRow) {
+		return nil, fmt.Errorf("header: missing hex or col and row columns")
+	}
+
+	var records []visibilityRecord
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		record := visibilityRecord{Player: fields[player]}
+		if hasHex {
+			record.Hex = fields[hex]
+		} else {
+			line, _ := cr.FieldPos(0)
+			colNo, err := strconv.Atoi(fields[col])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: col: %w", line, err)
+			}
+			rowNo, err := strconv.Atoi(fields[row])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: row: %w", line, err)
+			}
+			record.Col, record.Row = &colNo, &rowNo
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// FogOfWar returns a copy of the map that shows only the hexes that a
+// player has seen. The original map is not changed.
+//
+//   - The GM-only content is removed; see StripGMOnly.
+//   - Tiles that haven't been seen are replaced with blank tiles of the
+//     fog terrain, which is added to the terrain map if needed.
+//   - Features and labels that aren't in a seen hex are removed.
+//   - Shapes are removed unless every point is in a seen hex, since a
+//     partial shape could still show where something is.
+//   - Notes that aren't in a seen hex, or that have no location, are removed.
+//   - Information entries are kept only for the features that remain.
+//     Map-wide entries aren't tied to a hex and could describe any part
+//     of the map, so they are removed.
+//
+// Returns an error if a seen hex is not on the map.
+func FogOfWar(m *wxx.Map, seen []hexgrid.Hex, fogTerrain string) (*wxx.Map, error) {
+	if fogTerrain == "" {
+		fogTerrain = DefaultUnknownTerrain
+	}
+	c, err := StripGMOnly(m, fogTerrain)
+	if err != nil {
+		return nil, fmt.Errorf("fog: %w", err)
+	}
+	layout, err := hexgrid.NewLayout(c)
+	if err != nil {
+		return nil, fmt.Errorf("fog: %w", err)
+	}
+
+	visible := map[hexgrid.Hex]bool{}
+	for _, h := range seen {
+		if !layout.Grid.Contains(h) {
+			return nil, fmt.Errorf("fog: hex %s: not on the map", h)
+		}
+		visible[h] = true
+	}
+
+	terrain := -1 // only added to the terrain map if a tile needs it
+	for col := 0; col < c.Width(); col++ {
+		for row := 0; row < c.Height(); row++ {
+			if visible[hexgrid.Hex{Col: col, Row: row}] {
+				continue
+			} else if terrain == -1 {
+				terrain = c.TerrainIndex(fogTerrain)
+			}
+			if err = c.SetTile(col, row, &wxx.Tile{Terrain: terrain}); err != nil {
+				return nil, fmt.Errorf("fog: %w", err)
+			}
+		}
+	}
+
+	kept := map[string]bool{} // uuids of the features that remain
+	var features []*wxx.Feature
+	for _, f := range c.Features {
+		if h, ok := layout.FeatureHex(f); ok && visible[h] {
+			features = append(features, f)
+			if f.Uuid != "" {
+				kept[f.Uuid] = true
+			}
+		}
+	}
+	c.Features = features
+
+	var labels []*wxx.Label
+	for _, l := range c.Labels {
+		if h, ok := layout.LabelHex(l); ok && visible[h] {
+			labels = append(labels, l)
+		}
+	}
+	c.Labels = labels
+
+	var shapes []*wxx.Shape
+	for _, s := range c.Shapes {
+		isVisible := len(s.Points) != 0
+		for _, p := range s.Points {
+			if !visible[layout.PointHex(s, p)] {
+				isVisible = false
+				break
+			}
+		}
+		if isVisible {
+			shapes = append(shapes, s)
+		}
+	}
+	c.Shapes = shapes
+
+	var notes []*wxx.Note
+	for _, n := range c.Notes {
+		if h, ok := layout.NoteHex(n); ok && visible[h] {
+			notes = append(notes, n)
+		}
+	}
+	c.Notes = notes
+
+	var informations []*wxx.Information
+	for _, info := range c.Informations.Informations {
+		if !kept[info.Uuid] {
+			continue
+		}
+		var details []*wxx.InformationDetail
+		for _, detail := range info.Details {
+			if kept[detail.Uuid] {
+				details = append(details, detail)
+			}
+		}
+		info.Details = details
+		informations = append(informations, info)
+	}
+	c.Informations.Informations = informations
+
+	return c, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadVisibility(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := hexgrid.NewNumbering(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Visibility{
+		"alice": {{Col: 4, Row: 12}, {Col: 4, Row: 13}},
+		"bob":   {{Col: 0, Row: 1}},
+	}
+	for _, tc := range []struct {
+		format, input string
+	}{
+		{"csv", "player,hex\nalice,04.12\nalice,04.13\nbob,00.01\n"},
+		{"csv", "Player, Col, Row\nalice,4,12\nalice,4,13\nbob,0,1\n"},
+		{"json", `[{"player": "alice", "hex": "04.12"}, {"player": "alice", "col": 4, "row": 13}, {"player": "bob", "col": 0, "row": 1}]`},
+	} {
+		got, err := ReadVisibility(strings.NewReader(tc.input), tc.format, n)
+		if err != nil {
+			t.Errorf("%s: %q: %v", tc.format, tc.input, err)
+			continue
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: %q: got %d players, want %d", tc.format, tc.input, len(got), len(want))
+		}
+		for player, hexes := range want {
+			if len(got[player]) != len(hexes) {
+				t.Errorf("%s: %q: %s: got %v, want %v", tc.format, tc.input, player, got[player], hexes)
+				continue
+			}
+			for i := range hexes {
+				if got[player][i] != hexes[i] {
+					t.Errorf("%s: %q: %s: got %v, want %v", tc.format, tc.input, player, got[player], hexes)
+				}
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		format, input string
+	}{
+		{"csv", "name,hex\nalice,04.12\n"},
+		{"csv", "player,col\nalice,4\n"},
+		{"csv", "player,hex\nalice,0412\n"},
+		{"csv", "player,col,row\nalice,4,x\n"},
+		{"csv", "player,hex\n,04.12\n"},
+		{"json", `[{"player": "alice"}]`},
+		{"json", `{"alice": ["04.12"]}`},
+		{"xml", `<alice/>`},
+	} {
+		if _, err := ReadVisibility(strings.NewReader(tc.input), tc.format, n); err == nil {
+			t.Errorf("%s: %q: expected error", tc.format, tc.input)
+		}
+	}
+}
+
+func TestFogOfWar(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := hexgrid.Hex{Col: 0, Row: 0}
+	m.Shapes = append(m.Shapes, &wxx.Shape{Points: []*wxx.Point{{X: 20, Y: 20}, {X: 30, Y: 25}}})
+	m.Features = append(m.Features, &wxx.Feature{Uuid: "f3", MapLayer: "Features", Location: &wxx.FeatureLocation{ViewLevel: "WORLD", X: 23.09, Y: 20}})
+	m.Informations.Informations = append(m.Informations.Informations,
+		&wxx.Information{Uuid: "f1", Title: "The Capital"},
+		&wxx.Information{Uuid: "f3", Title: "The Inn", Details: []*wxx.InformationDetail{{Uuid: "f1"}, {Uuid: "f3"}, {Uuid: "d1"}}},
+		&wxx.Information{Uuid: "i1", Title: "The Realm"},
+	)
+	note := func(text, x, y string) *wxx.Note {
+		n := &wxx.Note{InnerText: text, Unknown: &wxx.Unknown{}}
+		if x != "" {
+			n.Unknown.Attrs = []wxx.Attr{{Name: "viewLevel", Value: "WORLD"}, {Name: "x", Value: x}, {Name: "y", Value: y}}
+		}
+		return n
+	}
+	m.Notes = append(m.Notes, note("The inn", "23.09", "20.0"), note("The capital", "57.725", "60.0"), note("Somewhere", "", ""))
+
+	pm, err := FogOfWar(m, []hexgrid.Hex{seen}, "Fog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fog, ok := pm.TerrainMap.Data["Fog"]
+	if !ok {
+		t.Fatalf("terrain map: fog terrain not added")
+	}
+	for col := 0; col < pm.Width(); col++ {
+		for row := 0; row < pm.Height(); row++ {
+			tile, original := pm.TileAt(col, row), m.TileAt(col, row)
+			if col == seen.Col && row == seen.Row {
+				if tile.Terrain != original.Terrain {
+					t.Errorf("tile %d,%d: got terrain %d, want %d", col, row, tile.Terrain, original.Terrain)
+				}
+			} else if tile.Terrain != fog {
+				t.Errorf("tile %d,%d: got terrain %d, want fog", col, row, tile.Terrain)
+			}
+		}
+	}
+	for _, f := range pm.Features {
+		if h, _ := layout.FeatureHex(f); h != seen {
+			t.Errorf("feature %s: in hex %s, which hasn't been seen", f.Uuid, h)
+		}
+	}
+	for _, l := range pm.Labels {
+		if h, _ := layout.LabelHex(l); h != seen {
+			t.Errorf("label %q: in hex %s, which hasn't been seen", l.InnerText, h)
+		}
+	}
+	if len(pm.Shapes) != 1 {
+		t.Errorf("shapes: got %d, want 1", len(pm.Shapes))
+	}
+	if len(pm.Notes) != 1 || pm.Notes[0].InnerText != "The inn" {
+		t.Errorf("notes: got %d, want only the note in the seen hex", len(pm.Notes))
+	}
+	if infos := pm.Informations.Informations; len(infos) != 1 || infos[0].Uuid != "f3" {
+		t.Errorf("informations: got %d, want only the entry for f3", len(infos))
+	} else if len(infos[0].Details) != 1 || infos[0].Details[0].Uuid != "f3" {
+		t.Errorf("informations: got %d details, want only the detail for f3", len(infos[0].Details))
+	}
+	if len(GMOnlyContent(pm)) != 0 {
+		t.Errorf("GM-only content remains")
+	}
+
+	if _, err = FogOfWar(m, []hexgrid.Hex{{Col: 5, Row: 5}}, ""); err == nil {
+		t.Errorf("hex off the map: expected error")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzDecode feeds hostile .wxx files to the full import pipeline.
+// ImportWXXFile is a thin wrapper that opens the file and calls Decode.
+// Decode may return an error but must never panic.
+func FuzzDecode(f *testing.F) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "maps", "*.xml"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, input := range inputs {
+		src, err := os.ReadFile(input)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encodeWXX(f, src))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m, err := Decode(bytes.NewReader(data), nil)
+		if err == nil && m == nil {
+			t.Fatal("no map and no error")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+)
+
+// DefaultUnknownTerrain is the terrain that GM-only tiles are changed to
+// when the caller doesn't choose one.
+const DefaultUnknownTerrain = "Blank"
+
+// StripGMOnly returns a copy of the map that is safe to send to players.
+// The original map is not changed.
+//
+//   - GM-only features, labels and shapes are removed.
+//   - GM-only tiles are replaced with blank tiles of the unknown terrain,
+//     which is added to the terrain map if needed.
+//   - Information entries for removed features are removed.
+//   - GM-only notes and information entries are removed. We don't model
+//     their flag, so it is read from their isGMOnly attribute.
+//   - The GM-only display settings are turned off.
+//
+// Returns an error if any GM-only content remains in the copy.
+func StripGMOnly(m *wxx.Map, unknownTerrain string) (*wxx.Map, error) {
+	if unknownTerrain == "" {
+		unknownTerrain = DefaultUnknownTerrain
+	}
+
+	c, err := copyMap(m)
+	if err != nil {
+		return nil, fmt.Errorf("strip-gm: %w", err)
+	}
+
+	c.ShowGMOnly, c.ShowGMOnlyGlow = false, false
+
+	terrain := -1 // only added to the terrain map if a tile needs it
+	for col := 0; col < c.Width(); col++ {
+		for row := 0; row < c.Height(); row++ {
+			if t := c.TileAt(col, row); t != nil && t.IsGMOnly {
+				if terrain == -1 {
+					terrain = c.TerrainIndex(unknownTerrain)
+				}
+				if err = c.SetTile(col, row, &wxx.Tile{Terrain: terrain}); err != nil {
+					return nil, fmt.Errorf("strip-gm: %w", err)
+				}
+			}
+		}
+	}
+
+	removed := map[string]bool{} // uuids of removed features
+	var features []*wxx.Feature
+	for _, f := range c.Features {
+		if f.IsGMOnly {
+			if f.Uuid != "" {
+				removed[f.Uuid] = true
+			}
+			continue
+		}
+		if f.Label != nil && f.Label.IsGMOnly {
+			f.Label = nil
+		}
+		features = append(features, f)
+	}
+	c.Features = features
+
+	var labels []*wxx.Label
+	for _, l := range c.Labels {
+		if !l.IsGMOnly {
+			labels = append(labels, l)
+		}
+	}
+	c.Labels = labels
+
+	var shapes []*wxx.Shape
+	for _, s := range c.Shapes {
+		if !s.IsGMOnly {
+			shapes = append(shapes, s)
+		}
+	}
+	c.Shapes = shapes
+
+	var notes []*wxx.Note
+	for _, n := range c.Notes {
+		if !isGMOnly(n.Unknown) {
+			notes = append(notes, n)
+		}
+	}
+	c.Notes = notes
+
+	var informations []*wxx.Information
+	for _, info := range c.Informations.Informations {
+		if isGMOnly(info.Unknown) {
+			continue
+		}
+		var details []*wxx.InformationDetail
+		for _, detail := range info.Details {
+			if !isGMOnly(detail.Unknown) {
+				details = append(details, detail)
+			}
+		}
+		info.Details = details
+		informations = append(informations, info)
+	}
+	c.Informations.Informations = informations
+
+	removeInformations(c, removed)
+
+	if found := GMOnlyContent(c); len(found) != 0 {
+		return nil, fmt.Errorf("strip-gm: %s: still GM-only", found[0])
+	}
+	return c, nil
+}
+
+// GMOnlyContent returns the paths of the GM-only content in the map,
+// e.g. "Features[3]" or "Tiles[2,7]".
+func GMOnlyContent(m *wxx.Map) []string {
+	var found []string
+	if m.ShowGMOnly {
+		found = append(found, "ShowGMOnly")
+	}
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			if t := m.TileAt(col, row); t != nil && t.IsGMOnly {
+				found = append(found, fmt.Sprintf("Tiles[%d,%d]", col, row))
+			}
+		}
+	}
+	for i, f := range m.Features {
+		if f.IsGMOnly {
+			found = append(found, fmt.Sprintf("Features[%d]", i))
+		} else if f.Label != nil && f.Label.IsGMOnly {
+			found = append(found, fmt.Sprintf("Features[%d].Label", i))
+		}
+	}
+	for i, l := range m.Labels {
+		if l.IsGMOnly {
+			found = append(found, fmt.Sprintf("Labels[%d]", i))
+		}
+	}
+	for i, s := range m.Shapes {
+		if s.IsGMOnly {
+			found = append(found, fmt.Sprintf("Shapes[%d]", i))
+		}
+	}
+	for i, n := range m.Notes {
+		if isGMOnly(n.Unknown) {
+			found = append(found, fmt.Sprintf("Notes[%d]", i))
+		}
+	}
+	for i, info := range m.Informations.Informations {
+		if isGMOnly(info.Unknown) {
+			found = append(found, fmt.Sprintf("Informations[%d]", i))
+			continue
+		}
+		for j, detail := range info.Details {
+			if isGMOnly(detail.Unknown) {
+				found = append(found, fmt.Sprintf("Informations[%d].Details[%d]", i, j))
+			}
+		}
+	}
+	return found
+}
+
+// isGMOnly returns true if the element has isGMOnly="true" in the
+// attributes that we don't model.
+func isGMOnly(u *wxx.Unknown) bool {
+	value, _ := u.Attr("isGMOnly")
+	return value == "true"
+}
+
+// copyMap returns a deep copy of the map.
+func copyMap(m *wxx.Map) (*wxx.Map, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("copy: %w", err)
+	}
+	c := &wxx.Map{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("copy: %w", err)
+	}
+	return c, nil
+}
+
+// removeInformations removes the information entries and details
+// for the removed features.
+func removeInformations(m *wxx.Map, removed map[string]bool) {
+	var informations []*wxx.Information
+	for _, info := range m.Informations.Informations {
+		if removed[info.Uuid] {
+			continue
+		}
+		var details []*wxx.InformationDetail
+		for _, detail := range info.Details {
+			if !removed[detail.Uuid] {
+				details = append(details, detail)
+			}
+		}
+		info.Details = details
+		informations = append(informations, info)
+	}
+	m.Informations.Informations = informations
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStripGMOnly(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeWXX(t, src)
+	m, err := Decode(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ShowGMOnly = true
+	secret := m.TileAt(1, 0)
+	secret.IsGMOnly, secret.Elevation, secret.Resources.Gems = true, 250, 3
+	// notes and information entries are only GM-only by their attribute
+	gmOnly := &wxx.Unknown{Attrs: []wxx.Attr{{Name: "isGMOnly", Value: "true"}}}
+	m.Informations.Informations = append(m.Informations.Informations,
+		&wxx.Information{Uuid: "f2", Title: "The Dungeon"},
+		&wxx.Information{Uuid: "i1", Title: "The Realm", Details: []*wxx.InformationDetail{{Uuid: "f2"}, {Uuid: "d1"}, {Uuid: "d2", Unknown: gmOnly}}},
+		&wxx.Information{Uuid: "i2", Title: "The Plot", Unknown: gmOnly},
+	)
+	m.Notes = append(m.Notes,
+		&wxx.Note{InnerText: "The mill is the cult's hideout.", Unknown: gmOnly},
+		&wxx.Note{InnerText: "Market day is Thursday.", Unknown: &wxx.Unknown{Attrs: []wxx.Attr{{Name: "isGMOnly", Value: "false"}}}},
+	)
+	found := GMOnlyContent(m)
+	for _, want := range []string{"Notes[0]", "Informations[1].Details[2]", "Informations[2]"} {
+		if !slices.Contains(found, want) {
+			t.Errorf("GMOnlyContent: got %v, want %s", found, want)
+		}
+	}
+	if slices.Contains(found, "Notes[1]") {
+		t.Errorf("GMOnlyContent: got %v, want no Notes[1]", found)
+	}
+
+	stripped, err := StripGMOnly(m, "Unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := GMOnlyContent(stripped); len(found) != 0 {
+		t.Errorf("GM-only content remains: %v", found)
+	}
+	if !secret.IsGMOnly || len(GMOnlyContent(m)) == 0 {
+		t.Errorf("the original map was changed")
+	}
+
+	for _, f := range stripped.Features {
+		if f.Uuid == "f2" {
+			t.Errorf("feature f2: not removed")
+		}
+	}
+	if len(stripped.Features) != len(m.Features)-1 {
+		t.Errorf("features: got %d, want %d", len(stripped.Features), len(m.Features)-1)
+	}
+	tile := stripped.TileAt(1, 0)
+	if index, ok := stripped.TerrainMap.Data["Unknown"]; !ok {
+		t.Errorf("terrain map: unknown terrain not added")
+	} else if tile.Terrain != index || tile.Elevation != 0 || tile.Resources.Gems != 0 {
+		t.Errorf("tile 1,0: got %+v, want a blank tile", *tile)
+	}
+	if infos := stripped.Informations.Informations; len(infos) != 1 || infos[0].Uuid != "i1" {
+		t.Errorf("informations: entries for f2 and GM-only i2 not removed")
+	} else if len(infos[0].Details) != 1 || infos[0].Details[0].Uuid != "d1" {
+		t.Errorf("informations: details for f2 and GM-only d2 not removed")
+	}
+	if notes := stripped.Notes; len(notes) != 1 || notes[0].InnerText != "Market day is Thursday." {
+		t.Errorf("notes: GM-only note not removed")
+	}
+
+	// the export option strips the map as it is written
+	var out bytes.Buffer
+	if err = Encode(&out, m, &Options{StripGM: true}); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := Decode(&out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found := GMOnlyContent(exported); len(found) != 0 {
+		t.Errorf("export: GM-only content remains: %v", found)
+	}
+	if tile := exported.TileAt(1, 0); tile.Terrain != exported.TerrainMap.Data[DefaultUnknownTerrain] {
+		t.Errorf("export: tile 1,0: got terrain %d, want %q", tile.Terrain, DefaultUnknownTerrain)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files instead of comparing against them:
+//
+//	go test -run TestGolden -update
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// TestGolden runs each map in testdata/maps through every stage of the
+// import and export pipelines and compares the results against the golden
+// JSON and XML files in testdata/golden.
+//
+// The maps are stored as UTF-8 XML so that they can be read in review;
+// the test converts them to .wxx before importing them.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "maps", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(inputs) == 0 {
+		t.Fatal("no maps in testdata/maps")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".xml")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wxxData := encodeWXX(t, src)
+
+			// import, one stage at a time
+			utf16, err := adapters.GZipToUTF16(wxxData)
+			if err != nil {
+				t.Fatalf("GZipToUTF16: %v", err)
+			}
+			utf8, err := adapters.UTF16ToUTF8(utf16)
+			if err != nil {
+				t.Fatalf("UTF16ToUTF8: %v", err)
+			} else if !bytes.Equal(utf8, src) {
+				t.Fatalf("UTF16ToUTF8: input was not restored")
+			}
+			wxml, err := adapters.UTF8ToWXML(utf8)
+			if err != nil {
+				t.Fatalf("UTF8ToWXML: %v", err)
+			}
+			m, err := adapters.WXMLToWXX(wxml, nil)
+			if err != nil {
+				t.Fatalf("WXMLToWXX: %v", err)
+			}
+			checkGolden(t, filepath.Join("testdata", "golden", name+".json"), marshalGoldenJSON(t, m))
+
+			// export, one stage at a time, in the map's own version if we
+			// can write it (we can't write v2.x)
+			version := m.Version
+			if _, err := adapters.LookupEncoder(version); err != nil {
+				version = adapters.DefaultVersion
+			}
+			codec, err := adapters.LookupEncoder(version)
+			if err != nil {
+				t.Fatalf("LookupEncoder: %v", err)
+			}
+			var xmlBuffer bytes.Buffer
+			if err = codec.Encode(&xmlBuffer, m, version); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			xmlData := xmlBuffer.Bytes()
+			checkGolden(t, filepath.Join("testdata", "golden", name+".xml"), xmlData)
+
+			// the streaming pipeline must agree with the stages
+			sm, err := Decode(bytes.NewReader(wxxData), nil)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			for _, diff := range Diff(m, sm) {
+				t.Errorf("Decode: %s", diff)
+			}
+			var out bytes.Buffer
+			if err = Encode(&out, m, &Options{TargetVersion: version}); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			utf16, err = adapters.GZipToUTF16(out.Bytes())
+			if err != nil {
+				t.Fatalf("Encode: GZipToUTF16: %v", err)
+			}
+			utf8, err = adapters.UTF16ToUTF8(utf16)
+			if err != nil {
+				t.Fatalf("Encode: UTF16ToUTF8: %v", err)
+			} else if got := bytes.TrimPrefix(utf8, []byte("<?xml version='1.0' encoding='utf-16'?>\n")); !bytes.Equal(got, xmlData) {
+				t.Errorf("Encode: output does not match the exported XML")
+			}
+		})
+	}
+}
+
+// encodeWXX converts UTF-8 XML to the .wxx format.
+func encodeWXX(t testing.TB, src []byte) []byte {
+	t.Helper()
+	utf16, err := adapters.UTF8ToUTF16(src)
+	if err != nil {
+		t.Fatalf("UTF8ToUTF16: %v", err)
+	}
+	data, err := adapters.UTF16ToGZip(utf16)
+	if err != nil {
+		t.Fatalf("UTF16ToGZip: %v", err)
+	}
+	return data
+}
+
+// decodeWXX converts the .wxx format to UTF-8 XML.
+func decodeWXX(t testing.TB, data []byte) []byte {
+	t.Helper()
+	utf16, err := adapters.GZipToUTF16(data)
+	if err != nil {
+		t.Fatalf("GZipToUTF16: %v", err)
+	}
+	utf8, err := adapters.UTF16ToUTF8(utf16)
+	if err != nil {
+		t.Fatalf("UTF16ToUTF8: %v", err)
+	}
+	return utf8
+}
+
+// marshalGoldenJSON returns the map as JSON without the timestamp
+// that changes every time the map is loaded.
+func marshalGoldenJSON(t *testing.T, m *wxx.Map) []byte {
+	t.Helper()
+	created := m.MetaData.Created
+	m.MetaData.Created = ""
+	defer func() {
+		m.MetaData.Created = created
+	}()
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	return append(data, '\n')
+}
+
+// checkGolden compares got against the golden file, or updates the
+// golden file if the -update flag is set.
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		gotLines, wantLines := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+			var g, w string
+			if i < len(gotLines) {
+				g = gotLines[i]
+			}
+			if i < len(wantLines) {
+				w = wantLines[i]
+			}
+			if g != w {
+				t.Fatalf("%s: line %d:\n got: %q\nwant: %q\n(run with -update to accept the change)", path, i+1, g, w)
+			}
+		}
+	}
+}
+
+// TestTileOrder checks that each tilerow in the file is a column of
+// tiles for both orientations.
+func TestTileOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		orientation string
+		terrain     [][]int // [col][row]
+	}{
+		{"basic", wxx.OrientationColumns, [][]int{{0, 2}, {2, 0}}},
+		{"rows", wxx.OrientationRows, [][]int{{0, 1}, {2, 3}, {1, 2}}},
+	} {
+		src, err := os.ReadFile(filepath.Join("testdata", "maps", tc.name+".xml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if m.HexOrientation != tc.orientation {
+			t.Errorf("%s: orientation: got %q, want %q", tc.name, m.HexOrientation, tc.orientation)
+		}
+		if m.Width() != len(tc.terrain) || m.Height() != len(tc.terrain[0]) {
+			t.Fatalf("%s: size: got %dx%d, want %dx%d", tc.name, m.Width(), m.Height(), len(tc.terrain), len(tc.terrain[0]))
+		}
+		for col, terrains := range tc.terrain {
+			for row, terrain := range terrains {
+				tile := m.TileAt(col, row)
+				if tile.Column != col || tile.Row != row {
+					t.Errorf("%s: %d,%d: got tile %d,%d", tc.name, col, row, tile.Column, tile.Row)
+				} else if tile.Terrain != terrain {
+					t.Errorf("%s: %d,%d: terrain: got %d, want %d", tc.name, col, row, tile.Terrain, terrain)
+				}
+			}
+		}
+	}
+}
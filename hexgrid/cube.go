@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"math"
+)
+
+// Axial is a hex in axial coordinates.
+// The third cube coordinate is implied by q + r + s = 0.
+type Axial struct {
+	Q, R int
+}
+
+// Cube returns the hex in cube coordinates.
+func (a Axial) Cube() Cube {
+	return Cube{Q: a.Q, R: a.R, S: -a.Q - a.R}
+}
+
+// Cube is a hex in cube coordinates. The coordinates always satisfy
+// q + r + s = 0, which makes distances and rotations simple.
+type Cube struct {
+	Q, R, S int
+}
+
+// Axial returns the hex in axial coordinates.
+func (c Cube) Axial() Axial {
+	return Axial{Q: c.Q, R: c.R}
+}
+
+// Add returns the sum of the two hexes.
+func (c Cube) Add(o Cube) Cube {
+	return Cube{Q: c.Q + o.Q, R: c.R + o.R, S: c.S + o.S}
+}
+
+// Sub returns the difference of the two hexes.
+func (c Cube) Sub(o Cube) Cube {
+	return Cube{Q: c.Q - o.Q, R: c.R - o.R, S: c.S - o.S}
+}
+
+// Scale returns the hex multiplied by k.
+func (c Cube) Scale(k int) Cube {
+	return Cube{Q: c.Q * k, R: c.R * k, S: c.S * k}
+}
+
+// Length returns the distance from the origin to the hex.
+func (c Cube) Length() int {
+	return max(abs(c.Q), abs(c.R), abs(c.S))
+}
+
+// Distance returns the number of steps between the two hexes.
+func (c Cube) Distance(o Cube) int {
+	return c.Sub(o).Length()
+}
+
+// directions are the six neighbors of the origin, clockwise from the
+// north-east (with y increasing down the map).
+var directions = [6]Cube{
+	{Q: 1, R: -1, S: 0},
+	{Q: 1, R: 0, S: -1},
+	{Q: 0, R: 1, S: -1},
+	{Q: -1, R: 1, S: 0},
+	{Q: -1, R: 0, S: 1},
+	{Q: 0, R: -1, S: 1},
+}
+
+// Direction returns the offset to the neighbor in direction d.
+// The direction is taken modulo 6.
+func Direction(d int) Cube {
+	return directions[((d%6)+6)%6]
+}
+
+// Neighbor returns the adjacent hex in direction d.
+func (c Cube) Neighbor(d int) Cube {
+	return c.Add(Direction(d))
+}
+
+// Neighbors returns the six adjacent hexes.
+func (c Cube) Neighbors() [6]Cube {
+	var neighbors [6]Cube
+	for d := range directions {
+		neighbors[d] = c.Neighbor(d)
+	}
+	return neighbors
+}
+
+// Ring returns the hexes that are exactly radius steps away.
+// A radius of 0 returns the hex itself.
+func (c Cube) Ring(radius int) []Cube {
+	if radius < 0 {
+		return nil
+	} else if radius == 0 {
+		return []Cube{c}
+	}
+	ring := make([]Cube, 0, 6*radius)
+	hex := c.Add(Direction(4).Scale(radius))
+	for d := 0; d < 6; d++ {
+		for i := 0; i < radius; i++ {
+			ring = append(ring, hex)
+			hex = hex.Neighbor(d)
+		}
+	}
+	return ring
+}
+
+// Spiral returns the hex followed by its rings, out to radius.
+func (c Cube) Spiral(radius int) []Cube {
+	var spiral []Cube
+	for r := 0; r <= radius; r++ {
+		spiral = append(spiral, c.Ring(r)...)
+	}
+	return spiral
+}
+
+// Range returns every hex within n steps, ordered by q and then r.
+func (c Cube) Range(n int) []Cube {
+	var hexes []Cube
+	for q := -n; q <= n; q++ {
+		for r := max(-n, -q-n); r <= min(n, -q+n); r++ {
+			hexes = append(hexes, c.Add(Cube{Q: q, R: r, S: -q - r}))
+		}
+	}
+	return hexes
+}
+
+// LineTo returns the hexes on a straight line from c to o, inclusive.
+func (c Cube) LineTo(o Cube) []Cube {
+	n := c.Distance(o)
+	if n == 0 {
+		return []Cube{c}
+	}
+	// nudge the end points so that lines along an edge between two
+	// hexes always round the same way
+	const epsilon = 1e-6
+	aq, ar, as := float64(c.Q)+epsilon, float64(c.R)+epsilon, float64(c.S)-2*epsilon
+	bq, br, bs := float64(o.Q)+epsilon, float64(o.R)+epsilon, float64(o.S)-2*epsilon
+	line := make([]Cube, 0, n+1)
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		line = append(line, Round(aq+(bq-aq)*t, ar+(br-ar)*t, as+(bs-as)*t))
+	}
+	return line
+}
+
+// Round returns the hex that contains the fractional cube coordinates.
+func Round(q, r, s float64) Cube {
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	} else {
+		rs = -rq - rr
+	}
+	return Cube{Q: int(rq), R: int(rr), S: int(rs)}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package hexgrid implements the geometry of a map's hexes.
+//
+// Tiles are addressed by offset coordinates (column and row), the same as
+// wxx.Map.TileAt. The math is done in cube coordinates and converted back
+// using the map's orientation and offset convention.
+package hexgrid
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+)
+
+// Orientation is the direction that the hexes are staggered.
+type Orientation int
+
+const (
+	// Columns are flat-topped hexes with every other column shifted
+	// by half a hex ("COLUMNS" in the .wxx file).
+	Columns Orientation = iota
+	// Rows are pointy-topped hexes with every other row shifted
+	// by half a hex ("ROWS" in the .wxx file).
+	Rows
+)
+
+func (o Orientation) String() string {
+	switch o {
+	case Columns:
+		return wxx.OrientationColumns
+	case Rows:
+		return wxx.OrientationRows
+	}
+	return fmt.Sprintf("Orientation(%d)", int(o))
+}
+
+// ParseOrientation returns the orientation for a hexOrientation value.
+func ParseOrientation(s string) (Orientation, error) {
+	switch s {
+	case wxx.OrientationColumns:
+		return Columns, nil
+	case wxx.OrientationRows:
+		return Rows, nil
+	}
+	return Columns, fmt.Errorf("hexOrientation %q: unknown orientation", s)
+}
+
+// Offset is the convention for which columns (or rows) are shifted.
+type Offset int
+
+const (
+	// Odd shifts the odd columns down (or the odd rows right).
+	// This is the convention Worldographer uses.
+	Odd Offset = iota
+	// Even shifts the even columns down (or the even rows right).
+	Even
+)
+
+func (o Offset) String() string {
+	switch o {
+	case Odd:
+		return "odd"
+	case Even:
+		return "even"
+	}
+	return fmt.Sprintf("Offset(%d)", int(o))
+}
+
+// Hex is a hex in offset coordinates.
+type Hex struct {
+	Col, Row int
+}
+
+func (h Hex) String() string {
+	return fmt.Sprintf("%d,%d", h.Col, h.Row)
+}
+
+// Grid converts between coordinate systems and answers geometry
+// questions for a map. If Width and Height are set, results are
+// clipped to the map.
+type Grid struct {
+	Orientation Orientation
+	Offset      Offset
+	Width       int // number of columns, or 0 if unbounded
+	Height      int // number of rows, or 0 if unbounded
+}
+
+// New returns a grid that matches the map's orientation and size.
+func New(m *wxx.Map) (*Grid, error) {
+	orientation, err := ParseOrientation(m.HexOrientation)
+	if err != nil {
+		return nil, err
+	}
+	return &Grid{Orientation: orientation, Offset: Odd, Width: m.Width(), Height: m.Height()}, nil
+}
+
+// Contains returns true if the hex is on the map.
+// An unbounded grid contains every hex.
+func (g *Grid) Contains(h Hex) bool {
+	if g.Width == 0 && g.Height == 0 {
+		return true
+	}
+	return 0 <= h.Col && h.Col < g.Width && 0 <= h.Row && h.Row < g.Height
+}
+
+// ToAxial converts offset coordinates to axial coordinates.
+func (g *Grid) ToAxial(h Hex) Axial {
+	if g.Orientation == Rows {
+		return Axial{Q: h.Col - g.shift(h.Row), R: h.Row}
+	}
+	return Axial{Q: h.Col, R: h.Row - g.shift(h.Col)}
+}
+
+// ToCube converts offset coordinates to cube coordinates.
+func (g *Grid) ToCube(h Hex) Cube {
+	return g.ToAxial(h).Cube()
+}
+
+// FromAxial converts axial coordinates to offset coordinates.
+func (g *Grid) FromAxial(a Axial) Hex {
+	if g.Orientation == Rows {
+		return Hex{Col: a.Q + g.shift(a.R), Row: a.R}
+	}
+	return Hex{Col: a.Q, Row: a.R + g.shift(a.Q)}
+}
+
+// FromCube converts cube coordinates to offset coordinates.
+func (g *Grid) FromCube(c Cube) Hex {
+	return g.FromAxial(c.Axial())
+}
+
+// shift returns the number of half-hex shifts before column (or row) n.
+// n&1 is 0 or 1 for negative numbers as well, so the division is exact.
+func (g *Grid) shift(n int) int {
+	if g.Offset == Even {
+		return (n + (n & 1)) / 2
+	}
+	return (n - (n & 1)) / 2
+}
+
+// Distance returns the number of steps between two hexes.
+func (g *Grid) Distance(a, b Hex) int {
+	return g.ToCube(a).Distance(g.ToCube(b))
+}
+
+// Neighbor returns the adjacent hex in direction d (0 to 5, clockwise from the north-east).
+// The neighbor may not be on the map.
+func (g *Grid) Neighbor(h Hex, d int) Hex {
+	return g.FromCube(g.ToCube(h).Neighbor(d))
+}
+
+// Neighbors returns the adjacent hexes that are on the map.
+func (g *Grid) Neighbors(h Hex) []Hex {
+	neighbors := g.ToCube(h).Neighbors()
+	return g.fromCubes(neighbors[:])
+}
+
+// Ring returns the hexes on the map that are exactly radius steps from center.
+func (g *Grid) Ring(center Hex, radius int) []Hex {
+	return g.fromCubes(g.ToCube(center).Ring(radius))
+}
+
+// Spiral returns center followed by its rings out to radius,
+// keeping only the hexes on the map.
+func (g *Grid) Spiral(center Hex, radius int) []Hex {
+	return g.fromCubes(g.ToCube(center).Spiral(radius))
+}
+
+// Range returns the hexes on the map that are within n steps of center.
+func (g *Grid) Range(center Hex, n int) []Hex {
+	return g.fromCubes(g.ToCube(center).Range(n))
+}
+
+// Line returns the hexes on a straight line from a to b, inclusive.
+// The line is not clipped, since both ends are given by the caller.
+func (g *Grid) Line(a, b Hex) []Hex {
+	var line []Hex
+	for _, c := range g.ToCube(a).LineTo(g.ToCube(b)) {
+		line = append(line, g.FromCube(c))
+	}
+	return line
+}
+
+// fromCubes converts the hexes to offset coordinates,
+// dropping any that are not on the map.
+func (g *Grid) fromCubes(cubes []Cube) []Hex {
+	var hexes []Hex
+	for _, c := range cubes {
+		if h := g.FromCube(c); g.Contains(h) {
+			hexes = append(hexes, h)
+		}
+	}
+	return hexes
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"github.com/mdhender/wxconv/models/wxx"
+	"sort"
+	"testing"
+)
+
+func TestOffsetRoundTrip(t *testing.T) {
+	for _, orientation := range []Orientation{Columns, Rows} {
+		for _, offset := range []Offset{Odd, Even} {
+			g := &Grid{Orientation: orientation, Offset: offset}
+			for col := -5; col <= 5; col++ {
+				for row := -5; row <= 5; row++ {
+					h := Hex{Col: col, Row: row}
+					c := g.ToCube(h)
+					if c.Q+c.R+c.S != 0 {
+						t.Errorf("%s/%s: %s: cube %v: q+r+s != 0", orientation, offset, h, c)
+					}
+					if got := g.FromCube(c); got != h {
+						t.Errorf("%s/%s: %s: round trip got %s", orientation, offset, h, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	for _, tc := range []struct {
+		orientation Orientation
+		offset      Offset
+		hex         Hex
+		want        []Hex
+	}{
+		// odd columns are shifted down
+		{Columns, Odd, Hex{2, 2}, []Hex{{1, 1}, {1, 2}, {2, 1}, {2, 3}, {3, 1}, {3, 2}}},
+		{Columns, Odd, Hex{3, 2}, []Hex{{2, 2}, {2, 3}, {3, 1}, {3, 3}, {4, 2}, {4, 3}}},
+		// even columns are shifted down
+		{Columns, Even, Hex{2, 2}, []Hex{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 2}, {3, 3}}},
+		// odd rows are shifted right
+		{Rows, Odd, Hex{2, 2}, []Hex{{1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 2}}},
+		{Rows, Odd, Hex{2, 3}, []Hex{{1, 3}, {2, 2}, {2, 4}, {3, 2}, {3, 3}, {3, 4}}},
+		// even rows are shifted right
+		{Rows, Even, Hex{2, 2}, []Hex{{1, 2}, {2, 1}, {2, 3}, {3, 1}, {3, 2}, {3, 3}}},
+	} {
+		g := &Grid{Orientation: tc.orientation, Offset: tc.offset}
+		got := sorted(g.Neighbors(tc.hex))
+		if !equal(got, tc.want) {
+			t.Errorf("%s/%s: %s: got %v, want %v", tc.orientation, tc.offset, tc.hex, got, tc.want)
+		}
+		for _, n := range got {
+			if d := g.Distance(tc.hex, n); d != 1 {
+				t.Errorf("%s/%s: %s: distance to %s: got %d, want 1", tc.orientation, tc.offset, tc.hex, n, d)
+			}
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	g := &Grid{Orientation: Columns, Offset: Odd}
+	for _, tc := range []struct {
+		a, b Hex
+		want int
+	}{
+		{Hex{0, 0}, Hex{0, 0}, 0},
+		{Hex{0, 0}, Hex{0, 5}, 5},
+		{Hex{0, 0}, Hex{5, 0}, 5},
+		{Hex{0, 0}, Hex{4, 4}, 6},
+		{Hex{3, 3}, Hex{-2, 1}, 5},
+	} {
+		if got := g.Distance(tc.a, tc.b); got != tc.want {
+			t.Errorf("%s to %s: got %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := g.Distance(tc.b, tc.a); got != tc.want {
+			t.Errorf("%s to %s: got %d, want %d", tc.b, tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestRingsAndSpirals(t *testing.T) {
+	center := Cube{Q: 2, R: -1, S: -1}
+	total := 0
+	for radius := 0; radius <= 4; radius++ {
+		ring := center.Ring(radius)
+		want := 6 * radius
+		if radius == 0 {
+			want = 1
+		}
+		if len(ring) != want {
+			t.Errorf("ring %d: got %d hexes, want %d", radius, len(ring), want)
+		}
+		for _, c := range ring {
+			if d := center.Distance(c); d != radius {
+				t.Errorf("ring %d: %v: distance %d", radius, c, d)
+			}
+		}
+		total += want
+		if spiral := center.Spiral(radius); len(spiral) != total {
+			t.Errorf("spiral %d: got %d hexes, want %d", radius, len(spiral), total)
+		} else if spiral[0] != center {
+			t.Errorf("spiral %d: does not start at the center", radius)
+		}
+		if hexes := center.Range(radius); len(hexes) != total {
+			t.Errorf("range %d: got %d hexes, want %d", radius, len(hexes), total)
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	g := &Grid{Orientation: Columns, Offset: Odd}
+	for _, tc := range []struct{ a, b Hex }{
+		{Hex{0, 0}, Hex{0, 0}},
+		{Hex{0, 0}, Hex{6, 0}},
+		{Hex{1, 4}, Hex{7, 1}},
+		{Hex{5, 5}, Hex{0, 2}},
+	} {
+		line := g.Line(tc.a, tc.b)
+		if len(line) != g.Distance(tc.a, tc.b)+1 {
+			t.Errorf("%s to %s: got %d hexes, want %d", tc.a, tc.b, len(line), g.Distance(tc.a, tc.b)+1)
+			continue
+		}
+		if line[0] != tc.a || line[len(line)-1] != tc.b {
+			t.Errorf("%s to %s: got %v", tc.a, tc.b, line)
+		}
+		for i := 1; i < len(line); i++ {
+			if d := g.Distance(line[i-1], line[i]); d != 1 {
+				t.Errorf("%s to %s: %s to %s: not adjacent", tc.a, tc.b, line[i-1], line[i])
+			}
+		}
+	}
+}
+
+func TestClipping(t *testing.T) {
+	m := &wxx.Map{HexOrientation: wxx.OrientationColumns}
+	m.Tiles.TilesWide, m.Tiles.TilesHigh = 4, 3
+	g, err := New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Neighbors(Hex{0, 0}); len(got) != 2 {
+		t.Errorf("neighbors of corner: got %v", got)
+	}
+	for _, h := range g.Range(Hex{1, 1}, 5) {
+		if !g.Contains(h) {
+			t.Errorf("range: %s: not on the map", h)
+		}
+	}
+	if got := len(g.Range(Hex{1, 1}, 10)); got != 12 {
+		t.Errorf("range: got %d hexes, want 12", got)
+	}
+
+	m.HexOrientation = "DIAGONAL"
+	if _, err = New(m); err == nil {
+		t.Errorf("orientation: expected error")
+	}
+}
+
+func sorted(hexes []Hex) []Hex {
+	sort.Slice(hexes, func(i, j int) bool {
+		if hexes[i].Col != hexes[j].Col {
+			return hexes[i].Col < hexes[j].Col
+		}
+		return hexes[i].Row < hexes[j].Row
+	})
+	return hexes
+}
+
+func equal(a, b []Hex) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
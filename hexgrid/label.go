@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"strconv"
+	"strings"
+)
+
+// Numbering formats and parses the grid labels that Worldographer draws
+// in each hex, e.g. "04.12".
+type Numbering struct {
+	FirstCol  int    // number of the first column
+	FirstRow  int    // number of the first row
+	RowFirst  bool   // true if the row is printed before the column
+	Digits    int    // minimum number of digits, padded with zeroes
+	Separator string // printed between the column and the row
+}
+
+// NewNumbering returns the numbering from the map's grid settings.
+func NewNumbering(m *wxx.Map) (*Numbering, error) {
+	g := m.GridAndNumbering
+	n := &Numbering{
+		FirstCol:  g.NumberFirstCol,
+		FirstRow:  g.NumberFirstRow,
+		Separator: g.NumberSeparator,
+	}
+	switch g.NumberOrder {
+	case "COL_ROW", "":
+	case "ROW_COL":
+		n.RowFirst = true
+	default:
+		return nil, fmt.Errorf("numberOrder %q: unknown order", g.NumberOrder)
+	}
+	// NONE prints the numbers as they are. ZERO pads them with zeroes to
+	// the width of the largest number on the map, so that the labels line
+	// up. DOUBLE_ZERO pads them to at least two digits, e.g. "04.12".
+	switch g.NumberPrePad {
+	case "NONE", "":
+		n.Digits = 0
+	case "ZERO":
+		n.Digits = max(numberWidth(n.FirstCol+max(m.Width()-1, 0)), numberWidth(n.FirstRow+max(m.Height()-1, 0)))
+	case "DOUBLE_ZERO":
+		n.Digits = 2
+	default:
+		return nil, fmt.Errorf("numberPrePad %q: unknown padding", g.NumberPrePad)
+	}
+	return n, nil
+}
+
+// Format returns the label for the hex.
+func (n *Numbering) Format(h Hex) string {
+	col := fmt.Sprintf("%0*d", n.Digits, h.Col+n.FirstCol)
+	row := fmt.Sprintf("%0*d", n.Digits, h.Row+n.FirstRow)
+	if n.RowFirst {
+		return row + n.Separator + col
+	}
+	return col + n.Separator + row
+}
+
+// Parse returns the hex for a label created by Format.
+// Without a separator, the numbers are split using the padding,
+// so labels with numbers wider than the padding can't be parsed.
+func (n *Numbering) Parse(label string) (Hex, error) {
+	var first, second string
+	if n.Separator != "" {
+		var ok bool
+		if first, second, ok = strings.Cut(label, n.Separator); !ok {
+			return Hex{}, fmt.Errorf("label %q: missing separator %q", label, n.Separator)
+		}
+	} else if n.Digits == 0 || len(label) != 2*n.Digits {
+		return Hex{}, fmt.Errorf("label %q: expected %d digits", label, 2*n.Digits)
+	} else {
+		first, second = label[:n.Digits], label[n.Digits:]
+	}
+	a, err := parseLabelNumber(first)
+	if err != nil {
+		return Hex{}, fmt.Errorf("label %q: %w", label, err)
+	}
+	b, err := parseLabelNumber(second)
+	if err != nil {
+		return Hex{}, fmt.Errorf("label %q: %w", label, err)
+	}
+	if n.RowFirst {
+		a, b = b, a
+	}
+	return Hex{Col: a - n.FirstCol, Row: b - n.FirstRow}, nil
+}
+
+// numberWidth returns the number of characters needed to print the number.
+func numberWidth(v int) int {
+	return len(strconv.Itoa(v))
+}
+
+// parseLabelNumber parses a number in a label. Only digits and a leading
+// minus sign are accepted, since Format never prints anything else.
+func parseLabelNumber(s string) (int, error) {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, fmt.Errorf("%q: invalid number", s)
+	}
+	return strconv.Atoi(s)
+}
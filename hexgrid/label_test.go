@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"github.com/mdhender/wxconv/models/wxx"
+	"testing"
+)
+
+func TestNumbering(t *testing.T) {
+	for _, tc := range []struct {
+		order, pad, sep    string
+		firstCol, firstRow int
+		hex                Hex
+		want               string
+	}{
+		{"COL_ROW", "DOUBLE_ZERO", ".", 0, 0, Hex{4, 12}, "04.12"},
+		{"COL_ROW", "ZERO", ".", 0, 0, Hex{4, 12}, "4.12"},
+		{"COL_ROW", "ZERO", "", 1, 1, Hex{3, 6}, "47"},
+		{"COL_ROW", "DOUBLE_ZERO", "", 1, 1, Hex{3, 11}, "0412"},
+		{"COL_ROW", "NONE", ",", 0, 0, Hex{4, 12}, "4,12"},
+		{"ROW_COL", "DOUBLE_ZERO", "-", 0, 0, Hex{4, 12}, "12-04"},
+		{"ROW_COL", "DOUBLE_ZERO", "", 1, 1, Hex{0, 98}, "9901"},
+		{"COL_ROW", "DOUBLE_ZERO", ".", 0, 0, Hex{123, 7}, "123.07"},
+		{"COL_ROW", "DOUBLE_ZERO", ".", -1, 0, Hex{0, 0}, "-1.00"},
+	} {
+		m := &wxx.Map{}
+		m.GridAndNumbering.NumberOrder = tc.order
+		m.GridAndNumbering.NumberPrePad = tc.pad
+		m.GridAndNumbering.NumberSeparator = tc.sep
+		m.GridAndNumbering.NumberFirstCol = tc.firstCol
+		m.GridAndNumbering.NumberFirstRow = tc.firstRow
+		n, err := NewNumbering(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := n.Format(tc.hex); got != tc.want {
+			t.Errorf("%s %s %q: format %s: got %q, want %q", tc.order, tc.pad, tc.sep, tc.hex, got, tc.want)
+		}
+		if got, err := n.Parse(tc.want); err != nil {
+			t.Errorf("%s %s %q: parse %q: %v", tc.order, tc.pad, tc.sep, tc.want, err)
+		} else if got != tc.hex {
+			t.Errorf("%s %s %q: parse %q: got %s, want %s", tc.order, tc.pad, tc.sep, tc.want, got, tc.hex)
+		}
+	}
+}
+
+// TestNumberingPrePad checks the labels for each padding on a small
+// map and on maps with two and three digit numbers.
+func TestNumberingPrePad(t *testing.T) {
+	for _, tc := range []struct {
+		pad           string
+		width, height int
+		hex           Hex
+		want          string
+	}{
+		{"NONE", 9, 9, Hex{4, 7}, "4.7"},
+		{"NONE", 30, 20, Hex{4, 12}, "4.12"},
+		{"NONE", 150, 20, Hex{4, 12}, "4.12"},
+		{"ZERO", 9, 9, Hex{4, 7}, "4.7"},
+		{"ZERO", 30, 20, Hex{4, 7}, "04.07"},
+		{"ZERO", 150, 20, Hex{4, 12}, "004.012"},
+		{"DOUBLE_ZERO", 9, 9, Hex{4, 7}, "04.07"},
+		{"DOUBLE_ZERO", 30, 20, Hex{4, 12}, "04.12"},
+		{"DOUBLE_ZERO", 150, 20, Hex{4, 12}, "04.12"},
+	} {
+		m := &wxx.Map{}
+		m.Tiles.TilesWide, m.Tiles.TilesHigh = tc.width, tc.height
+		m.GridAndNumbering.NumberPrePad = tc.pad
+		m.GridAndNumbering.NumberSeparator = "."
+		n, err := NewNumbering(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := n.Format(tc.hex); got != tc.want {
+			t.Errorf("%s %dx%d: format %s: got %q, want %q", tc.pad, tc.width, tc.height, tc.hex, got, tc.want)
+		}
+		if got, err := n.Parse(tc.want); err != nil {
+			t.Errorf("%s %dx%d: parse %q: %v", tc.pad, tc.width, tc.height, tc.want, err)
+		} else if got != tc.hex {
+			t.Errorf("%s %dx%d: parse %q: got %s, want %s", tc.pad, tc.width, tc.height, tc.want, got, tc.hex)
+		}
+	}
+}
+
+func TestNumberingErrors(t *testing.T) {
+	m := &wxx.Map{}
+	m.GridAndNumbering.NumberOrder = "DIAGONAL"
+	if _, err := NewNumbering(m); err == nil {
+		t.Errorf("order: expected error")
+	}
+	m.GridAndNumbering.NumberOrder, m.GridAndNumbering.NumberPrePad = "COL_ROW", "TRIPLE_ZERO"
+	if _, err := NewNumbering(m); err == nil {
+		t.Errorf("padding: expected error")
+	}
+
+	n := &Numbering{Digits: 2, Separator: "."}
+	for _, label := range []string{"", "0412", "04.", ".12", "04.1x", "+4.12", "04.12.01"} {
+		if h, err := n.Parse(label); err == nil {
+			t.Errorf("parse %q: got %s, expected error", label, h)
+		}
+	}
+	n = &Numbering{Separator: ""}
+	if h, err := n.Parse("412"); err == nil {
+		t.Errorf("parse without padding: got %s, expected error", h)
+	}
+}
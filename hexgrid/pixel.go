@@ -0,0 +1,212 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"github.com/mdhender/wxconv/models/wxx"
+	"strconv"
+)
+
+// View levels, from the coarsest to the finest.
+var viewLevels = []string{"WORLD", "CONTINENT", "KINGDOM", "PROVINCE"}
+
+// Point is a position in pixel space.
+type Point struct {
+	X, Y float64
+}
+
+// Layout converts between pixel space, where features, labels and shapes
+// are placed, and the hexes of the tiles.
+//
+// Hex 0,0 has its top-left corner at the origin. For flat-topped hexes,
+// columns are 3/4 of HexWidth apart and rows are HexHeight apart; for
+// pointy-topped hexes, columns are HexWidth apart and rows are 3/4 of
+// HexHeight apart.
+type Layout struct {
+	Grid      *Grid
+	HexWidth  float64
+	HexHeight float64
+
+	// ViewLevel is the view level of the tiles.
+	ViewLevel string
+	// Offsets is added to a position at the WORLD view level
+	// to get the position at each view level.
+	Offsets map[string]Point
+}
+
+// NewLayout returns the layout for the map's tiles.
+func NewLayout(m *wxx.Map) (*Layout, error) {
+	g, err := New(m)
+	if err != nil {
+		return nil, err
+	}
+	l := &Layout{
+		Grid:      g,
+		HexWidth:  m.HexWidth,
+		HexHeight: m.HexHeight,
+		ViewLevel: m.Tiles.ViewLevel,
+		Offsets:   map[string]Point{},
+	}
+	if l.ViewLevel == "" {
+		l.ViewLevel = viewLevels[0]
+	}
+	// the offsets in the map are between adjacent view levels
+	steps := []Point{
+		{X: m.WorldToContinentHOffset, Y: m.WorldToContinentVOffset},
+		{X: m.ContinentToKingdomHOffset, Y: m.ContinentToKingdomVOffset},
+		{X: m.KingdomToProvinceHOffset, Y: m.KingdomToProvinceVOffset},
+	}
+	var offset Point
+	for i, level := range viewLevels {
+		if i != 0 {
+			offset.X, offset.Y = offset.X+steps[i-1].X, offset.Y+steps[i-1].Y
+		}
+		l.Offsets[level] = offset
+	}
+	return l, nil
+}
+
+// ToTiles converts a position at a view level to the pixel space of the tiles.
+// Unknown view levels are treated as the view level of the tiles.
+func (l *Layout) ToTiles(viewLevel string, p Point) Point {
+	from, ok := l.Offsets[viewLevel]
+	if !ok {
+		return p
+	}
+	to := l.Offsets[l.ViewLevel]
+	return Point{X: p.X - from.X + to.X, Y: p.Y - from.Y + to.Y}
+}
+
+// FromTiles converts a position in the pixel space of the tiles to a view level.
+func (l *Layout) FromTiles(viewLevel string, p Point) Point {
+	to, ok := l.Offsets[viewLevel]
+	if !ok {
+		return p
+	}
+	from := l.Offsets[l.ViewLevel]
+	return Point{X: p.X - from.X + to.X, Y: p.Y - from.Y + to.Y}
+}
+
+// origin returns the center of axial hex 0,0. The even offset shifts the
+// first column (or row), which moves every hex by half a hex.
+func (l *Layout) origin() Point {
+	o := Point{X: l.HexWidth / 2, Y: l.HexHeight / 2}
+	if l.Grid.Offset == Even {
+		if l.Grid.Orientation == Rows {
+			o.X += l.HexWidth / 2
+		} else {
+			o.Y += l.HexHeight / 2
+		}
+	}
+	return o
+}
+
+// Center returns the pixel at the center of the hex.
+func (l *Layout) Center(h Hex) Point {
+	a, o := l.Grid.ToAxial(h), l.origin()
+	q, r := float64(a.Q), float64(a.R)
+	if l.Grid.Orientation == Rows {
+		return Point{X: o.X + l.HexWidth*(q+r/2), Y: o.Y + l.HexHeight*0.75*r}
+	}
+	return Point{X: o.X + l.HexWidth*0.75*q, Y: o.Y + l.HexHeight*(r+q/2)}
+}
+
+// Corners returns the pixels at the corners of the hex, clockwise
+// from the right-most corner (or the lower right corner for rows).
+func (l *Layout) Corners(h Hex) [6]Point {
+	c := l.Center(h)
+	w, ht := l.HexWidth, l.HexHeight
+	var d [6]Point
+	if l.Grid.Orientation == Rows {
+		d = [6]Point{{w / 2, ht / 4}, {0, ht / 2}, {-w / 2, ht / 4}, {-w / 2, -ht / 4}, {0, -ht / 2}, {w / 2, -ht / 4}}
+	} else {
+		d = [6]Point{{w / 2, 0}, {w / 4, ht / 2}, {-w / 4, ht / 2}, {-w / 2, 0}, {-w / 4, -ht / 2}, {w / 4, -ht / 2}}
+	}
+	for i := range d {
+		d[i] = Point{X: c.X + d[i].X, Y: c.Y + d[i].Y}
+	}
+	return d
+}
+
+// HexAt returns the hex that contains the pixel.
+// The hex may not be on the map.
+func (l *Layout) HexAt(p Point) Hex {
+	o := l.origin()
+	x, y := p.X-o.X, p.Y-o.Y
+	var q, r float64
+	if l.Grid.Orientation == Rows {
+		r = y / (l.HexHeight * 0.75)
+		q = x/l.HexWidth - r/2
+	} else {
+		q = x / (l.HexWidth * 0.75)
+		r = y/l.HexHeight - q/2
+	}
+	return l.Grid.FromCube(Round(q, r, -q-r))
+}
+
+// FeatureHex returns the hex that contains the feature.
+// Returns false if the feature has no location.
+func (l *Layout) FeatureHex(f *wxx.Feature) (Hex, bool) {
+	if f == nil || f.Location == nil {
+		return Hex{}, false
+	}
+	return l.HexAt(l.ToTiles(f.Location.ViewLevel, Point{X: f.Location.X, Y: f.Location.Y})), true
+}
+
+// LabelHex returns the hex that contains the label's anchor.
+// Returns false if the label has no location.
+func (l *Layout) LabelHex(lbl *wxx.Label) (Hex, bool) {
+	if lbl == nil || lbl.Location == nil {
+		return Hex{}, false
+	}
+	return l.HexAt(l.ToTiles(lbl.Location.ViewLevel, Point{X: lbl.Location.X, Y: lbl.Location.Y})), true
+}
+
+// NoteHex returns the hex that contains the note. We don't model the
+// note's location, so it is read from its viewLevel, x and y attributes.
+// Returns false if the note has no location.
+func (l *Layout) NoteHex(n *wxx.Note) (Hex, bool) {
+	if n == nil {
+		return Hex{}, false
+	}
+	viewLevel, _ := n.Unknown.Attr("viewLevel")
+	x, okX := n.Unknown.Attr("x")
+	y, okY := n.Unknown.Attr("y")
+	if !okX || !okY {
+		return Hex{}, false
+	}
+	var p Point
+	var err error
+	if p.X, err = strconv.ParseFloat(x, 64); err != nil {
+		return Hex{}, false
+	} else if p.Y, err = strconv.ParseFloat(y, 64); err != nil {
+		return Hex{}, false
+	}
+	return l.HexAt(l.ToTiles(viewLevel, p)), true
+}
+
+// PointHex returns the hex that contains a point of the shape.
+func (l *Layout) PointHex(s *wxx.Shape, p *wxx.Point) Hex {
+	return l.HexAt(l.ToTiles(s.CurrentShapeViewLevel, Point{X: p.X, Y: p.Y}))
+}
+
+// FeaturesIn returns the features of the map that are in the hex.
+func (l *Layout) FeaturesIn(m *wxx.Map, h Hex) []*wxx.Feature {
+	var features []*wxx.Feature
+	for _, f := range m.Features {
+		if fh, ok := l.FeatureHex(f); ok && fh == h {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// PlaceFeature moves the feature to the center of the hex,
+// keeping its view level if it has one.
+func (l *Layout) PlaceFeature(f *wxx.Feature, h Hex) {
+	if f.Location == nil {
+		f.Location = &wxx.FeatureLocation{ViewLevel: l.ViewLevel}
+	}
+	p := l.FromTiles(f.Location.ViewLevel, l.Center(h))
+	f.Location.X, f.Location.Y = p.X, p.Y
+}
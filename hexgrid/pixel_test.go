@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package hexgrid
+
+import (
+	"github.com/mdhender/wxconv/models/wxx"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestCenter(t *testing.T) {
+	const w, h = 46.18, 40.0
+	for _, tc := range []struct {
+		orientation Orientation
+		offset      Offset
+		hex         Hex
+		want        Point
+	}{
+		{Columns, Odd, Hex{0, 0}, Point{w / 2, h / 2}},
+		{Columns, Odd, Hex{1, 0}, Point{w/2 + w*0.75, h}},
+		{Columns, Odd, Hex{2, 3}, Point{w/2 + w*1.5, h/2 + 3*h}},
+		{Columns, Even, Hex{0, 0}, Point{w / 2, h}},
+		{Columns, Even, Hex{1, 0}, Point{w/2 + w*0.75, h / 2}},
+		{Rows, Odd, Hex{0, 0}, Point{w / 2, h / 2}},
+		{Rows, Odd, Hex{0, 1}, Point{w, h/2 + h*0.75}},
+		{Rows, Even, Hex{0, 0}, Point{w, h / 2}},
+		{Rows, Even, Hex{0, 1}, Point{w / 2, h/2 + h*0.75}},
+	} {
+		l := &Layout{Grid: &Grid{Orientation: tc.orientation, Offset: tc.offset}, HexWidth: w, HexHeight: h}
+		if got := l.Center(tc.hex); !near(got, tc.want) {
+			t.Errorf("%s/%s: %s: got %v, want %v", tc.orientation, tc.offset, tc.hex, got, tc.want)
+		}
+	}
+}
+
+func TestHexAt(t *testing.T) {
+	for _, orientation := range []Orientation{Columns, Rows} {
+		for _, offset := range []Offset{Odd, Even} {
+			l := &Layout{Grid: &Grid{Orientation: orientation, Offset: offset}, HexWidth: 46.18, HexHeight: 40}
+			for col := 0; col < 8; col++ {
+				for row := 0; row < 8; row++ {
+					h := Hex{Col: col, Row: row}
+					c := l.Center(h)
+					if got := l.HexAt(c); got != h {
+						t.Errorf("%s/%s: center of %s: got %s", orientation, offset, h, got)
+					}
+					// points just inside the corners belong to the hex
+					for _, corner := range l.Corners(h) {
+						p := Point{X: c.X + (corner.X-c.X)*0.95, Y: c.Y + (corner.Y-c.Y)*0.95}
+						if got := l.HexAt(p); got != h {
+							t.Errorf("%s/%s: corner of %s: got %s", orientation, offset, h, got)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	m := &wxx.Map{HexOrientation: wxx.OrientationColumns, HexWidth: 46.18, HexHeight: 40}
+	m.Tiles.ViewLevel, m.Tiles.TilesWide, m.Tiles.TilesHigh = "WORLD", 10, 10
+	m.WorldToContinentHOffset, m.WorldToContinentVOffset = 100, 50
+	l, err := NewLayout(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world := &wxx.Feature{Uuid: "world", Location: &wxx.FeatureLocation{ViewLevel: "WORLD"}}
+	continent := &wxx.Feature{Uuid: "continent", Location: &wxx.FeatureLocation{ViewLevel: "CONTINENT"}}
+	l.PlaceFeature(world, Hex{4, 12})
+	l.PlaceFeature(continent, Hex{4, 12})
+	if p := l.Center(Hex{4, 12}); world.Location.X != p.X || world.Location.Y != p.Y {
+		t.Errorf("world: got %v,%v, want %v", world.Location.X, world.Location.Y, p)
+	}
+	if world.Location.X+100 != continent.Location.X || world.Location.Y+50 != continent.Location.Y {
+		t.Errorf("continent: got %v,%v", continent.Location.X, continent.Location.Y)
+	}
+	m.Features = []*wxx.Feature{world, continent, {Uuid: "elsewhere"}}
+	if got := l.FeaturesIn(m, Hex{4, 12}); len(got) != 2 {
+		t.Errorf("features in 4,12: got %d, want 2", len(got))
+	}
+	if got := l.FeaturesIn(m, Hex{4, 11}); len(got) != 0 {
+		t.Errorf("features in 4,11: got %d, want 0", len(got))
+	}
+
+	note := func(attrs ...string) *wxx.Note {
+		n := &wxx.Note{Unknown: &wxx.Unknown{}}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			n.Unknown.Attrs = append(n.Unknown.Attrs, wxx.Attr{Name: attrs[i], Value: attrs[i+1]})
+		}
+		return n
+	}
+	p := l.Center(Hex{4, 12})
+	x, y := strconv.FormatFloat(p.X, 'f', -1, 64), strconv.FormatFloat(p.Y, 'f', -1, 64)
+	if h, ok := l.NoteHex(note("viewLevel", "WORLD", "x", x, "y", y)); !ok || h != (Hex{4, 12}) {
+		t.Errorf("note: got %s %v, want 4,12", h, ok)
+	}
+	for _, n := range []*wxx.Note{nil, {}, note("x", x), note("x", x, "y", "north")} {
+		if h, ok := l.NoteHex(n); ok {
+			t.Errorf("note without a location: got %s", h)
+		}
+	}
+}
+
+func near(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
@@ -6,101 +6,64 @@ import (
 	"fmt"
 	"github.com/mdhender/wxconv/adapters"
 	"github.com/mdhender/wxconv/models/wxx"
-	"log"
 	"os"
-	"path/filepath"
 	"time"
 )
 
-func ImportJSONFile(path string, debug bool, debugOutpathPath string) (*wxx.Map, error) {
-	return nil, fmt.Errorf("not implemented")
-}
-
-func ImportWXXFile(path string, debug bool, debugOutputPath string) (*wxx.Map, error) {
+// ImportJSONFile loads a map from a JSON file created by ExportJSONFile.
+func ImportJSONFile(path string, opts *Options) (*wxx.Map, error) {
 	started := time.Now()
-	step := started
-
-	var inputUtf8xml, inputUtf16xml string
-	if debugOutputPath != "" {
-		inputUtf8xml = filepath.Join(debugOutputPath, "input-utf-8.xml")
-		inputUtf16xml = filepath.Join(debugOutputPath, "input-utf-16.xml")
-	}
 
 	// read input
-	step = time.Now()
 	src, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	if debug {
-		log.Printf("debug: read      input             in %v\n", time.Now().Sub(step))
+	opts.timing("read", time.Since(started))
+
+	if err = opts.context().Err(); err != nil {
+		return nil, err
 	}
 
-	// unzip input and convert to UTF-16
-	step = time.Now()
-	src, err = adapters.GZipToUTF16(src)
+	// convert the JSON to WMAP
+	step := time.Now()
+	wmap, err := adapters.JSONToWXX(src)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	if debug {
-		log.Printf("debug: converted gzip to utf-16    in %v\n", time.Now().Sub(step))
-	}
-
-	if inputUtf16xml != "" {
-		// save UTF-16 input
-		step = time.Now()
-		if err = os.WriteFile(inputUtf16xml, src, 0644); err != nil {
-			return nil, err
-		}
-		if debug {
-			log.Printf("debug: created   input-utf-16.xml  in %v\n", time.Now().Sub(step))
-		}
-	}
+	opts.timing("json", time.Since(step))
 
-	// convert input from UTF-16 to UTF-8
 	step = time.Now()
-	src, err = adapters.UTF16ToUTF8(src)
-	if err != nil {
+	if err = opts.transform(wmap); err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	if debug {
-		log.Printf("debug: converted utf-16 to utf-8   in %v\n", time.Now().Sub(step))
-	}
+	opts.timing("transform", time.Since(step))
 
-	if inputUtf8xml != "" {
-		// save UTF-8 input
-		step = time.Now()
-		if err = os.WriteFile(inputUtf8xml, src, 0644); err != nil {
-			return nil, err
-		}
-		if debug {
-			log.Printf("debug: created   input-utf-8.xml   in %v\n", time.Now().Sub(step))
-		}
-	}
+	opts.timing("import", time.Since(started))
 
-	// convert UTF-8 to WXML
-	step = time.Now()
-	wxml, err := adapters.UTF8ToWXML(src)
+	return wmap, nil
+}
+
+// ImportWXXFile loads a map from a .wxx file.
+func ImportWXXFile(path string, opts *Options) (*wxx.Map, error) {
+	started := time.Now()
+
+	// open input
+	fd, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	if debug {
-		log.Printf("debug: converted utf-8 to wxml     in %v\n", time.Now().Sub(step))
-	}
+	defer func(fd *os.File) {
+		_ = fd.Close() // ignore errors
+	}(fd)
 
-	// convert the WXML to WMAP
-	step = time.Now()
-	wmap, err := adapters.WXMLToWXX(wxml)
+	// stream the input through gzip, utf-16, utf-8, wxml, and wmap
+	wmap, err := Decode(fd, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", path, err)
 	}
-	if debug {
-		log.Printf("debug: converted wxml to wmap      in %v\n", time.Now().Sub(step))
-	}
 
-	if debug {
-		log.Printf("debug: completed import            in %v\n", time.Now().Sub(started))
-	}
+	opts.timing("import", time.Since(started))
 
 	return wmap, nil
 }
@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdhender/wxconv/adapters"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportJSONFile(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "map.json")
+	if err = ExportJSONFile(m, path, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportJSONFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range Diff(m, got) {
+		t.Errorf("round trip: %s", diff)
+	}
+
+	// a file from a newer version of this application is rejected
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer := filepath.Join(dir, "newer.json")
+	data = bytes.Replace(data, []byte(`"version": "0.0.1"`), []byte(`"version": "9.0.0"`), 1)
+	if err = os.WriteFile(newer, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ImportJSONFile(newer, nil); !errors.Is(err, adapters.ErrUnsupportedMetaData) {
+		t.Errorf("newer: got %v, want %v", err, adapters.ErrUnsupportedMetaData)
+	}
+
+	if _, err = ImportJSONFile(filepath.Join(dir, "missing.json"), nil); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing: got %v, want %v", err, os.ErrNotExist)
+	}
+}
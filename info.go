@@ -0,0 +1,174 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"sort"
+)
+
+// Summary describes what a map contains.
+type Summary struct {
+	Version     string `json:"version"`
+	Type        string `json:"type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Orientation string `json:"orientation"`
+	ViewLevel   string `json:"viewLevel"`
+
+	// Terrain is the number of tiles of each terrain, by name.
+	Terrain []Count `json:"terrain"`
+	// Features is the number of features of each type.
+	Features []Count `json:"features"`
+	Labels   int     `json:"labels"`
+	Shapes   int     `json:"shapes"`
+	Notes    int     `json:"notes"`
+	// Informations is the number of information entries of each type,
+	// including the details nested in other entries.
+	Informations []Count `json:"informations"`
+
+	Layers []wxx.MapLayer `json:"layers"`
+
+	// GMOnly is the number of items that are hidden from players.
+	// Notes and informations don't model the flag, so they are counted
+	// from their isGMOnly attribute, the same way StripGMOnly finds them.
+	GMOnly struct {
+		Tiles         int `json:"tiles"`
+		Features      int `json:"features"`
+		FeatureLabels int `json:"featureLabels"`
+		Labels        int `json:"labels"`
+		Shapes        int `json:"shapes"`
+		Notes         int `json:"notes"`
+		// Informations includes the details nested in other entries.
+		Informations int `json:"informations"`
+	} `json:"gmOnly"`
+
+	// Resources are the totals for all the tiles.
+	Resources struct {
+		Animal int `json:"animal"`
+		Brick  int `json:"brick"`
+		Crops  int `json:"crops"`
+		Gems   int `json:"gems"`
+		Lumber int `json:"lumber"`
+		Metals int `json:"metals"`
+		Rock   int `json:"rock"`
+	} `json:"resources"`
+}
+
+// Count is the number of items with a name.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Summarize returns a summary of the map.
+// The counts are sorted by count, largest first, then by name.
+func Summarize(m *wxx.Map) *Summary {
+	s := &Summary{
+		Version:     m.Version,
+		Type:        m.Type,
+		Width:       m.Width(),
+		Height:      m.Height(),
+		Orientation: m.HexOrientation,
+		ViewLevel:   m.Tiles.ViewLevel,
+		Labels:      len(m.Labels),
+		Shapes:      len(m.Shapes),
+		Notes:       len(m.Notes),
+	}
+
+	terrainNames := map[int]string{}
+	for _, t := range m.TerrainMap.List {
+		terrainNames[t.Index] = t.Label
+	}
+	terrain := map[string]int{}
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			t := m.TileAt(col, row)
+			if t == nil {
+				continue
+			}
+			name, ok := terrainNames[t.Terrain]
+			if !ok {
+				name = fmt.Sprintf("#%d", t.Terrain)
+			}
+			terrain[name]++
+			if t.IsGMOnly {
+				s.GMOnly.Tiles++
+			}
+			s.Resources.Animal += t.Resources.Animal
+			s.Resources.Brick += t.Resources.Brick
+			s.Resources.Crops += t.Resources.Crops
+			s.Resources.Gems += t.Resources.Gems
+			s.Resources.Lumber += t.Resources.Lumber
+			s.Resources.Metals += t.Resources.Metals
+			s.Resources.Rock += t.Resources.Rock
+		}
+	}
+	s.Terrain = counts(terrain)
+
+	features := map[string]int{}
+	for _, f := range m.Features {
+		features[f.Type]++
+		if f.IsGMOnly {
+			s.GMOnly.Features++
+		}
+		if f.Label != nil && f.Label.IsGMOnly {
+			s.GMOnly.FeatureLabels++
+		}
+	}
+	s.Features = counts(features)
+
+	for _, l := range m.Labels {
+		if l.IsGMOnly {
+			s.GMOnly.Labels++
+		}
+	}
+	for _, sh := range m.Shapes {
+		if sh.IsGMOnly {
+			s.GMOnly.Shapes++
+		}
+	}
+	for _, n := range m.Notes {
+		if isGMOnly(n.Unknown) {
+			s.GMOnly.Notes++
+		}
+	}
+
+	informations := map[string]int{}
+	for _, i := range m.Informations.Informations {
+		informations[i.Type]++
+		if isGMOnly(i.Unknown) {
+			s.GMOnly.Informations++
+		}
+		for _, d := range i.Details {
+			informations[d.Type]++
+			if isGMOnly(d.Unknown) {
+				s.GMOnly.Informations++
+			}
+		}
+	}
+	s.Informations = counts(informations)
+
+	s.Layers = make([]wxx.MapLayer, 0, len(m.MapLayer))
+	for _, l := range m.MapLayer {
+		s.Layers = append(s.Layers, wxx.MapLayer{Name: l.Name, IsVisible: l.IsVisible})
+	}
+
+	return s
+}
+
+// counts returns the counts sorted by count, largest first, then by name.
+func counts(m map[string]int) []Count {
+	list := make([]Count, 0, len(m))
+	for name, count := range m {
+		list = append(list, Count{Name: name, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.TileAt(1, 1).IsGMOnly = true
+	m.TileAt(1, 1).Resources.Gems = 3
+	m.TileAt(0, 1).Resources.Gems = 4
+
+	s := Summarize(m)
+	if s.Width != 2 || s.Height != 2 || s.Orientation != "COLUMNS" {
+		t.Errorf("size: got %dx%d %s, want 2x2 COLUMNS", s.Width, s.Height, s.Orientation)
+	}
+	if want := []Count{{"Blank", 2}, {"Flat Grazing Land", 2}}; !reflect.DeepEqual(s.Terrain, want) {
+		t.Errorf("terrain: got %v, want %v", s.Terrain, want)
+	}
+	if want := []Count{{"Dungeon", 1}, {"Settlement City", 1}}; !reflect.DeepEqual(s.Features, want) {
+		t.Errorf("features: got %v, want %v", s.Features, want)
+	}
+	if s.Labels != 2 {
+		t.Errorf("labels: got %d, want 2", s.Labels)
+	}
+	if g := s.GMOnly; g.Tiles != 1 || g.Features != 1 || g.FeatureLabels != 1 || g.Labels != 1 || g.Shapes != 0 {
+		t.Errorf("gm-only: got %+v, want 1 tile, 1 feature, 1 feature label, 1 label", g)
+	}
+	if s.Resources.Gems != 7 {
+		t.Errorf("gems: got %d, want 7", s.Resources.Gems)
+	}
+	if len(s.Layers) != len(m.MapLayer) {
+		t.Errorf("layers: got %d, want %d", len(s.Layers), len(m.MapLayer))
+	}
+}
+
+func TestSummarizeGMOnlyText(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "shapes-informations-notes.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := Summarize(m).GMOnly; g.Notes != 0 || g.Informations != 0 {
+		t.Errorf("gm-only: got %d notes, %d informations, want none", g.Notes, g.Informations)
+	}
+
+	// notes and informations only have the flag as an unmodelled attribute
+	gmOnly := &wxx.Unknown{Attrs: []wxx.Attr{{Name: "isGMOnly", Value: "true"}}}
+	m.Notes[0].Unknown = gmOnly
+	m.Informations.Informations[0].Unknown = gmOnly
+	m.Informations.Informations[0].Details[0].Unknown = gmOnly
+	if g := Summarize(m).GMOnly; g.Notes != 1 || g.Informations != 2 {
+		t.Errorf("gm-only: got %d notes, %d informations, want 1 note, 2 informations", g.Notes, g.Informations)
+	}
+}
@@ -5,6 +5,7 @@ package tmap173
 import (
 	"bytes"
 	_ "embed"
+	"io"
 	"text/template"
 )
 
@@ -15,14 +16,18 @@ var (
 
 // Encode marshals the Map to XML using custom templates.
 func (m *Map) Encode() ([]byte, error) {
-	t, err := template.New("xml-1.73").Parse(xmlTemplate)
-	if err != nil {
-		return nil, err
-	}
 	b := &bytes.Buffer{}
-	err = t.Execute(b, m)
-	if err != nil {
+	if err := m.EncodeTo(b); err != nil {
 		return nil, err
 	}
 	return b.Bytes(), nil
 }
+
+// EncodeTo marshals the Map to XML using custom templates and writes it to w.
+func (m *Map) EncodeTo(w io.Writer) error {
+	t, err := template.New("xml-1.73").Funcs(funcs).Parse(xmlTemplate)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, m)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package tmap173
+
+import (
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// funcs are the functions that the templates use to escape values
+// and to find the position of an element among its siblings.
+var funcs = template.FuncMap{
+	"add":   func(a, b int) int { return a + b },
+	"attr":  escapeAttr,
+	"cdata": escapeCDATA,
+	"text":  escapeText,
+}
+
+// escapeAttr escapes a value for use inside a double-quoted attribute.
+// Whitespace other than spaces is escaped so that parsers don't normalize it.
+func escapeAttr(s string) string {
+	return escape(s, true)
+}
+
+// escapeText escapes a value for use as the text of an element.
+// Worldographer writes new-lines in text as "&#10;", so we do, too.
+func escapeText(s string) string {
+	return escape(s, false)
+}
+
+// escapeCDATA returns the value wrapped in a CDATA section.
+// A CDATA section can't contain "]]>", so the value is split into
+// two sections wherever it occurs. Carriage returns are written as
+// character references between sections since parsers normalize
+// them to new-lines inside of a section.
+func escapeCDATA(s string) string {
+	s = strings.ReplaceAll(replaceInvalid(s), "]]>", "]]]]><![CDATA[>")
+	s = strings.ReplaceAll(s, "\r", "]]>&#13;<![CDATA[")
+	return "<![CDATA[" + s + "]]>"
+}
+
+func escape(s string, isAttr bool) string {
+	sb := strings.Builder{}
+	for _, r := range replaceInvalid(s) {
+		switch r {
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '"':
+			if isAttr {
+				sb.WriteString("&quot;")
+			} else {
+				sb.WriteRune(r)
+			}
+		case '\t':
+			if isAttr {
+				sb.WriteString("&#9;")
+			} else {
+				sb.WriteRune(r)
+			}
+		case '\n':
+			sb.WriteString("&#10;")
+		case '\r':
+			sb.WriteString("&#13;")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// replaceInvalid replaces runes that aren't allowed in XML 1.0 documents
+// with the Unicode replacement character. They can't be written, even
+// when escaped, and would make the file unreadable.
+func replaceInvalid(s string) string {
+	for _, r := range s {
+		if !isValidXMLRune(r) {
+			return strings.Map(func(r rune) rune {
+				if !isValidXMLRune(r) {
+					return utf8.RuneError
+				}
+				return r
+			}, s)
+		}
+	}
+	return s
+}
+
+// isValidXMLRune implements the Char production from the XML 1.0 spec.
+func isValidXMLRune(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		(r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) ||
+		(r >= 0x10000 && r <= 0x10FFFF)
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package tmap173
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{escapeAttr, `a "quoted" <b> & c`, `a &quot;quoted&quot; &lt;b&gt; &amp; c`},
+		{escapeAttr, "tab\tnew\nline\rreturn", "tab&#9;new&#10;line&#13;return"},
+		{escapeAttr, "nul\x00", "nul�"},
+		{escapeText, `a "quoted" <b> & c`, `a "quoted" &lt;b&gt; &amp; c`},
+		{escapeText, "Here be\ndragons", "Here be&#10;dragons"},
+		{escapeText, "tab\tstays", "tab\tstays"},
+		{escapeCDATA, "plain <b>text</b>", "<![CDATA[plain <b>text</b>]]>"},
+		{escapeCDATA, "a]]>b", "<![CDATA[a]]]]><![CDATA[>b]]>"},
+		{escapeCDATA, "", "<![CDATA[]]>"},
+		{escapeCDATA, "a\r\nb", "<![CDATA[a]]>&#13;<![CDATA[\nb]]>"},
+	} {
+		if got := tc.fn(tc.in); got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
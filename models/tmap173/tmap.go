@@ -61,6 +61,8 @@ type Map struct {
 		NumberPosition              string //
 		NumberPrePad                string //
 		NumberSeparator             string //
+
+		Unknown Unknown
 	}
 
 	TerrainMap string
@@ -73,6 +75,8 @@ type Map struct {
 		TilesHigh string // "21"
 
 		TileRows []string
+
+		Unknown Unknown
 	}
 
 	MapKey struct {
@@ -99,6 +103,8 @@ type Map struct {
 		EntryFontBold     string
 		EntryFontItalic   string
 		EntryScale        string
+
+		Unknown Unknown
 	}
 
 	Features []*Feature
@@ -113,13 +119,24 @@ type Map struct {
 	InformationInnerText string
 
 	Configuration struct {
-		TerrainConfig TerrainConfig
-		FeatureConfig FeatureConfig
-		TextureConfig TextureConfig
+		TerrainConfig []*TerrainConfig
+		FeatureConfig []*FeatureConfig
+		TextureConfig []*TextureConfig
 		TextConfig    TextConfig
 		ShapeConfig   ShapeConfig
 		InnerText     string
+
+		Unknown Unknown
 	}
+
+	// attributes and elements of the map and of the containers
+	// that don't have a type of their own.
+	Unknown             Unknown
+	UnknownFeatures     Unknown
+	UnknownLabels       Unknown
+	UnknownShapes       Unknown
+	UnknownNotes        Unknown
+	UnknownInformations Unknown
 }
 
 type Feature struct {
@@ -147,16 +164,32 @@ type Feature struct {
 
 	Location *FeatureLocation
 	Label    *Label
+
+	Unknown Unknown
 }
 
 type FeatureConfig struct {
+	Features  []*FeatureType
 	InnerText string `json:"innerText,omitempty"`
+
+	Unknown Unknown
+}
+
+type FeatureType struct {
+	Name  string
+	Image string
+	Color string
+	Tags  string
+
+	Unknown Unknown
 }
 
 type FeatureLocation struct {
 	ViewLevel string
 	X         string
 	Y         string
+
+	Unknown Unknown
 }
 
 type Information struct {
@@ -174,6 +207,8 @@ type Information struct {
 
 	Details   []*InformationDetail
 	InnerText string
+
+	Unknown Unknown
 }
 
 type InformationDetail struct {
@@ -189,6 +224,8 @@ type InformationDetail struct {
 	HolySymbol   string
 	Domains      string
 	InnerText    string
+
+	Unknown Unknown
 }
 
 type Label struct {
@@ -211,6 +248,8 @@ type Label struct {
 
 	Location  *LabelLocation
 	InnerText string
+
+	Unknown Unknown
 }
 
 type LabelLocation struct {
@@ -218,6 +257,8 @@ type LabelLocation struct {
 	X         string
 	Y         string
 	Scale     string
+
+	Unknown Unknown
 }
 
 type LabelStyle struct {
@@ -230,22 +271,30 @@ type LabelStyle struct {
 	BackgroundColor string
 	OutlineSize     string
 	OutlineColor    string
+
+	Unknown Unknown
 }
 
 type MapLayer struct {
 	// attributes
 	Name      string
 	IsVisible bool
+
+	Unknown Unknown
 }
 
 type Note struct {
 	InnerText string
+
+	Unknown Unknown
 }
 
 type Point struct {
 	Type string
 	X    string
 	Y    string
+
+	Unknown Unknown
 }
 
 type Shape struct {
@@ -290,11 +339,15 @@ type Shape struct {
 	Type                  string
 
 	Points []*Point
+
+	Unknown Unknown
 }
 
 type ShapeConfig struct {
 	ShapeStyles []*ShapeStyle
 	InnerText   string
+
+	Unknown Unknown
 }
 
 type ShapeStyle struct {
@@ -325,17 +378,59 @@ type ShapeStyle struct {
 	FillPaint     string
 	DsColor       string
 	InsColor      string
+
+	Unknown Unknown
 }
 
 type TerrainConfig struct {
+	Terrains  []*TerrainType
 	InnerText string
+
+	Unknown Unknown
+}
+
+type TerrainType struct {
+	Name  string
+	Image string
+	Color string
+	Tags  string
+
+	Unknown Unknown
 }
 
 type TextConfig struct {
 	LabelStyles []*LabelStyle
 	InnerText   string
+
+	Unknown Unknown
 }
 
 type TextureConfig struct {
+	Textures  []*TextureType
 	InnerText string
+
+	Unknown Unknown
+}
+
+type TextureType struct {
+	Name  string
+	Image string
+	Tags  string
+
+	Unknown Unknown
+}
+
+// Unknown holds the attributes and elements that we don't model,
+// already formatted as XML. Attrs starts with a space if it isn't empty.
+// Before holds the elements that are written before the known element
+// at that position; Elements holds the ones written after all of them.
+type Unknown struct {
+	Attrs    string
+	Before   map[int]string
+	Elements string
+}
+
+// At returns the elements to write before the known element at the position.
+func (u Unknown) At(position int) string {
+	return u.Before[position]
 }
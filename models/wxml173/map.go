@@ -51,6 +51,22 @@ type Map struct {
 	Notes            Notes            `xml:"notes"`
 	Informations     Informations     `xml:"informations"`
 	Configuration    Configuration    `xml:"configuration"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
+}
+
+// AnyElement captures an element that we don't model.
+type AnyElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+
+	// Position is the number of known elements before this one in its
+	// parent. It is only set for the children of the containers that
+	// decode their children in order.
+	Position int `xml:"-"`
 }
 
 type Configuration struct {
@@ -60,6 +76,10 @@ type Configuration struct {
 	TextureConfig []TextureConfig `xml:"texture-config"`
 	TextConfig    []TextConfig    `xml:"text-config"`
 	ShapeConfig   []ShapeConfig   `xml:"shape-config"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Feature struct {
@@ -92,19 +112,49 @@ type Feature struct {
 		ViewLevel string  `xml:"viewLevel,attr"`
 		X         float64 `xml:"x,attr"`
 		Y         float64 `xml:"y,attr"`
+
+		// unknown attributes and elements, kept so that they can be written back out
+		UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+		UnknownElements []AnyElement `xml:",any"`
 	} `xml:"location"`
 	Label     Label  `xml:"label"`
 	InnerText string `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type FeatureConfig struct {
 	// elements
-	InnerText string `xml:",chardata"`
+	Features  []FeatureType `xml:"feature"`
+	InnerText string        `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
+}
+
+// FeatureType is a custom feature icon.
+type FeatureType struct {
+	// attributes
+	Name  string `xml:"name,attr"`
+	Image string `xml:"image,attr"`
+	Color string `xml:"color,attr"`
+	Tags  string `xml:"tags,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Features struct {
 	// elements
 	Features []Feature `xml:"feature"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type GridAndNumbering struct {
@@ -140,6 +190,10 @@ type GridAndNumbering struct {
 	NumberPosition              string  `xml:"numberPosition,attr"`
 	NumberPrePad                string  `xml:"numberPrePad,attr"`
 	NumberSeparator             string  `xml:"numberSeparator,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Information struct {
@@ -161,12 +215,20 @@ type Information struct {
 	// elements
 	Details   []Information `xml:"information"`
 	InnerText string        `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Informations struct {
 	// elements
 	Informations []Information `xml:"information"`
 	InnerText    string        `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Label struct {
@@ -195,13 +257,25 @@ type Label struct {
 		X         float64 `xml:"x,attr"`
 		Y         float64 `xml:"y,attr"`
 		Scale     float64 `xml:"scale,attr"`
+
+		// unknown attributes and elements, kept so that they can be written back out
+		UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+		UnknownElements []AnyElement `xml:",any"`
 	} `xml:"location"`
 	InnerText string `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Labels struct {
 	// elements
 	Labels []Label `xml:"label"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type LabelStyle struct {
@@ -215,6 +289,10 @@ type LabelStyle struct {
 	BackgroundColor string  `xml:"backgroundColor,attr"`
 	OutlineSize     float64 `xml:"outlineSize,attr"`
 	OutlineColor    string  `xml:"outlineColor,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type MapKey struct {
@@ -242,21 +320,37 @@ type MapKey struct {
 	EntryFontBold     bool    `xml:"entryFontBold,attr"`
 	EntryFontItalic   bool    `xml:"entryFontItalic,attr"`
 	EntryScale        float64 `xml:"entryScale,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type MapLayer struct {
 	// attributes
 	Name      string `xml:"name,attr"`
 	IsVisible bool   `xml:"isVisible,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Note struct {
 	// elements
 	InnerText string `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Notes struct {
 	Notes []Note `xml:"note"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Point struct {
@@ -264,6 +358,10 @@ type Point struct {
 	Type string  `xml:"type,attr"`
 	X    float64 `xml:"x,attr"`
 	Y    float64 `xml:"y,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Shape struct {
@@ -310,12 +408,20 @@ type Shape struct {
 
 	// elements
 	Points []Point `xml:"p"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type ShapeConfig struct {
 	// elements
 	ShapeStyles []ShapeStyle `xml:"shapestyle"`
 	InnerText   string       `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type ShapeStyle struct {
@@ -347,32 +453,83 @@ type ShapeStyle struct {
 	StrokeType    string  `xml:"strokeType,attr"`
 	StrokeWidth   float64 `xml:"strokeWidth,attr"`
 	Tags          string  `xml:"tags,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Shapes struct {
 	// elements
 	Shapes []Shape `xml:"shape"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type TerrainConfig struct {
 	// elements
-	InnerText string `xml:",chardata"`
+	Terrains  []TerrainType `xml:"terrain"`
+	InnerText string        `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type TerrainMap struct {
 	// elements
 	InnerText string `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
+}
+
+// TerrainType is a custom terrain.
+type TerrainType struct {
+	// attributes
+	Name  string `xml:"name,attr"`
+	Image string `xml:"image,attr"`
+	Color string `xml:"color,attr"`
+	Tags  string `xml:"tags,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type TextConfig struct {
 	// elements
 	LabelStyles []LabelStyle `xml:"labelstyle"`
 	InnerText   string       `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type TextureConfig struct {
 	// elements
-	InnerText string `xml:",chardata"`
+	Textures  []TextureType `xml:"texture"`
+	InnerText string        `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
+}
+
+// TextureType is a custom texture.
+type TextureType struct {
+	// attributes
+	Name  string `xml:"name,attr"`
+	Image string `xml:"image,attr"`
+	Tags  string `xml:"tags,attr"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type Tiles struct {
@@ -383,9 +540,17 @@ type Tiles struct {
 
 	// elements
 	TileRows []TileRow `xml:"tilerow"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
 
 type TileRow struct {
 	// elements
 	InnerText string `xml:",chardata"`
+
+	// unknown attributes and elements, kept so that they can be written back out
+	UnknownAttrs    []xml.Attr   `xml:",any,attr"`
+	UnknownElements []AnyElement `xml:",any"`
 }
@@ -0,0 +1,297 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxml173
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The standard decoder collects unknown elements into the ",any" field
+// but forgets where they were among the elements that we model. The
+// containers decode their children themselves so that each unknown
+// element can record its Position, and the encoder can write it back
+// out in the same place. The decoder also replaces namespace prefixes
+// with URIs; the map puts the prefixes back once it has been decoded.
+
+func (m *Map) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Map
+	if err := decodeInOrder(d, start, (*plain)(m)); err != nil {
+		return err
+	}
+	restorePrefixes(reflect.ValueOf(m).Elem())
+	return nil
+}
+
+func (c *Configuration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Configuration
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (f *Feature) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Feature
+	return decodeInOrder(d, start, (*plain)(f))
+}
+
+func (c *FeatureConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain FeatureConfig
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (f *Features) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Features
+	return decodeInOrder(d, start, (*plain)(f))
+}
+
+func (i *Information) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Information
+	return decodeInOrder(d, start, (*plain)(i))
+}
+
+func (i *Informations) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Informations
+	return decodeInOrder(d, start, (*plain)(i))
+}
+
+func (l *Label) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Label
+	return decodeInOrder(d, start, (*plain)(l))
+}
+
+func (l *Labels) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Labels
+	return decodeInOrder(d, start, (*plain)(l))
+}
+
+func (n *Notes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Notes
+	return decodeInOrder(d, start, (*plain)(n))
+}
+
+func (s *Shape) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Shape
+	return decodeInOrder(d, start, (*plain)(s))
+}
+
+func (c *ShapeConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ShapeConfig
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (s *Shapes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Shapes
+	return decodeInOrder(d, start, (*plain)(s))
+}
+
+func (c *TerrainConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TerrainConfig
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (c *TextConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TextConfig
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (c *TextureConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TextureConfig
+	return decodeInOrder(d, start, (*plain)(c))
+}
+
+func (t *Tiles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Tiles
+	return decodeInOrder(d, start, (*plain)(t))
+}
+
+// decodeInOrder decodes the element into v, which must be a pointer to a
+// struct without an UnmarshalXML method. The attributes are decoded by the
+// standard decoder. The child elements are decoded one at a time so that
+// each unknown element records the number of known elements before it.
+//
+// If the element's text comes in more than one piece, as it does when a
+// CDATA section is followed by child elements, the pieces that are only
+// spaces, tabs and new-lines are the layout around the children and are
+// dropped. A carriage return between CDATA sections is text, since the
+// layout can't have one; parsers normalize them to new-lines. Text that
+// comes in one piece is kept exactly as written.
+func decodeInOrder(d *xml.Decoder, start xml.StartElement, v any) error {
+	// decode the attributes from the start tag alone
+	attrs := xml.NewTokenDecoder(&tokenList{tokens: []xml.Token{start, start.End()}})
+	if err := attrs.Decode(v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	fields := fieldsOf(rv.Type())
+	known := 0
+	var text []string
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if i, ok := fields.elements[t.Name.Local]; ok {
+				f := rv.Field(i)
+				if f.Kind() == reflect.Slice {
+					f.Set(reflect.Append(f, reflect.Zero(f.Type().Elem())))
+					f = f.Index(f.Len() - 1)
+				}
+				if err = d.DecodeElement(f.Addr().Interface(), &t); err != nil {
+					return err
+				}
+				known++
+				continue
+			}
+			var e AnyElement
+			if err = d.DecodeElement(&e, &t); err != nil {
+				return err
+			}
+			e.Position = known
+			if fields.unknown >= 0 {
+				f := rv.Field(fields.unknown)
+				f.Set(reflect.Append(f, reflect.ValueOf(e)))
+			}
+		case xml.CharData:
+			text = append(text, string(t))
+		case xml.EndElement:
+			if fields.text >= 0 {
+				rv.Field(fields.text).SetString(joinText(text))
+			}
+			return nil
+		}
+	}
+}
+
+// joinText joins the pieces of an element's text. If there is more than
+// one piece, the pieces that are only layout are dropped.
+func joinText(pieces []string) string {
+	if len(pieces) == 1 {
+		return pieces[0]
+	}
+	var sb strings.Builder
+	for _, piece := range pieces {
+		if strings.Trim(piece, " \t\n") != "" {
+			sb.WriteString(piece)
+		}
+	}
+	return sb.String()
+}
+
+// restorePrefixes replaces the namespace URIs in the names of the unknown
+// attributes and elements with the prefixes that the document bound them
+// to. The decoder replaces prefixes with URIs, but the names must be
+// written back out with their prefixes.
+func restorePrefixes(v reflect.Value) {
+	prefixes := map[string]string{xmlURL: "xml"}
+	walkUnknown(v, func(attr *xml.Attr) {
+		// the declarations, e.g. xmlns:wg="https://...", keep their names
+		if attr.Name.Space == "xmlns" {
+			prefixes[attr.Value] = attr.Name.Local
+		} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			prefixes[attr.Value] = "" // the default namespace has no prefix
+		}
+	}, nil)
+	walkUnknown(v, nil, func(name *xml.Name) {
+		if prefix, ok := prefixes[name.Space]; ok {
+			name.Space = prefix
+		}
+	})
+}
+
+// xmlURL is the namespace that the decoder gives to names with the "xml" prefix.
+const xmlURL = "http://www.w3.org/XML/1998/namespace"
+
+var (
+	attrsType   = reflect.TypeOf([]xml.Attr(nil))
+	elementType = reflect.TypeOf(AnyElement{})
+)
+
+// walkUnknown calls fAttr for each unknown attribute and fName for the name
+// of each unknown attribute and element in the value. Either may be nil.
+func walkUnknown(v reflect.Value, fAttr func(*xml.Attr), fName func(*xml.Name)) {
+	switch {
+	case v.Type() == attrsType:
+		for i := 0; i < v.Len(); i++ {
+			attr := v.Index(i).Addr().Interface().(*xml.Attr)
+			if fAttr != nil {
+				fAttr(attr)
+			}
+			if fName != nil {
+				fName(&attr.Name)
+			}
+		}
+	case v.Type() == elementType:
+		e := v.Addr().Interface().(*AnyElement)
+		if fName != nil {
+			fName(&e.XMLName)
+		}
+		walkUnknown(v.FieldByName("Attrs"), fAttr, fName)
+	case v.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			walkUnknown(v.Elem(), fAttr, fName)
+		}
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkUnknown(v.Index(i), fAttr, fName)
+		}
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				walkUnknown(v.Field(i), fAttr, fName)
+			}
+		}
+	}
+}
+
+// structFields are the fields of a struct that hold child elements.
+type structFields struct {
+	elements map[string]int // index of the field for each element name
+	text     int            // index of the ",chardata" field, or -1
+	unknown  int            // index of the ",any" field, or -1
+}
+
+var fieldCache sync.Map // reflect.Type -> *structFields
+
+// fieldsOf returns the fields of the struct type that hold child elements.
+func fieldsOf(t reflect.Type) *structFields {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(*structFields)
+	}
+	fields := &structFields{elements: map[string]int{}, text: -1, unknown: -1}
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("xml")
+		if !ok || tag == "-" || t.Field(i).Name == "XMLName" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		switch {
+		case flags == "chardata":
+			fields.text = i
+		case flags == "any":
+			fields.unknown = i
+		case flags == "" && name != "":
+			fields.elements[name] = i
+		}
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// tokenList is a token reader over a fixed list of tokens.
+type tokenList struct {
+	tokens []xml.Token
+}
+
+func (l *tokenList) Token() (xml.Token, error) {
+	if len(l.tokens) == 0 {
+		return nil, io.EOF
+	}
+	tok := l.tokens[0]
+	l.tokens = l.tokens[1:]
+	return tok, nil
+}
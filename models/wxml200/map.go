@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package wxml200 defines the types required to read a Worldographer 2025 (v2.x) file.
+//
+// Reading v2.x files is best-effort. The v2.x schema hasn't been checked
+// against files saved by Worldographer 2025, so the elements are read with
+// the v1.73 element types. Attributes and elements that those types don't
+// model are kept in their unknown fields. The Map is a distinct type so
+// that the adapters can tell the versions apart.
+//
+// There is no v2.x encoder; a map read from a v2.x file is written as v1.73.
+package wxml200
+
+import (
+	"encoding/xml"
+	"github.com/mdhender/wxconv/models/wxml173"
+	"strings"
+)
+
+// Version is the version that the codec is registered under.
+const Version = "2.00"
+
+// IsVersion returns true if the version string is a v2.x version,
+// which is "2." followed by the minor version number, e.g. "2.00".
+func IsVersion(version string) bool {
+	major, minor, ok := strings.Cut(version, ".")
+	if !ok || major != "2" || minor == "" {
+		return false
+	}
+	for _, ch := range minor {
+		if !('0' <= ch && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// BaseVersion implements the adapter.WXML interface.
+func (m *Map) BaseVersion() string {
+	return m.Version
+}
+
+type Map wxml173.Map
+
+// UnmarshalXML decodes the map with the v1.73 decoder so that unknown
+// elements keep their place among the known elements.
+func (m *Map) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*wxml173.Map)(m).UnmarshalXML(d, start)
+}
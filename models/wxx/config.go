@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxx
+
+// AddTerrainType adds a custom terrain to the map. The terrain is added
+// to the terrain configuration and to the terrain map so that tiles can
+// use it. If the map already has a custom terrain with the same name, it
+// is replaced. Returns the index to use for Tile.Terrain.
+func (m *Map) AddTerrainType(t *TerrainType) int {
+	for _, config := range m.Configuration.TerrainConfig {
+		for i, terrain := range config.Terrains {
+			if terrain.Name == t.Name {
+				config.Terrains[i] = t
+				return m.TerrainIndex(t.Name)
+			}
+		}
+	}
+	if len(m.Configuration.TerrainConfig) == 0 {
+		m.Configuration.TerrainConfig = append(m.Configuration.TerrainConfig, &TerrainConfig{})
+	}
+	config := m.Configuration.TerrainConfig[0]
+	config.Terrains = append(config.Terrains, t)
+	return m.TerrainIndex(t.Name)
+}
+
+// TerrainIndex returns the index of the terrain in the terrain map.
+// If the terrain isn't in the map, it is added with the next free index.
+func (m *Map) TerrainIndex(label string) int {
+	if m.TerrainMap.Data == nil {
+		m.TerrainMap.Data = map[string]int{}
+	}
+	if index, ok := m.TerrainMap.Data[label]; ok {
+		return index
+	}
+	index := 0
+	for _, terrain := range m.TerrainMap.List {
+		if terrain.Index >= index {
+			index = terrain.Index + 1
+		}
+	}
+	m.TerrainMap.List = append(m.TerrainMap.List, &Terrain{Index: index, Label: label})
+	m.TerrainMap.Data[label] = index
+	return index
+}
+
+// AddFeatureType adds a custom feature icon to the map.
+// If the map already has one with the same name, it is replaced.
+func (m *Map) AddFeatureType(f *FeatureType) {
+	for _, config := range m.Configuration.FeatureConfig {
+		for i, feature := range config.Features {
+			if feature.Name == f.Name {
+				config.Features[i] = f
+				return
+			}
+		}
+	}
+	if len(m.Configuration.FeatureConfig) == 0 {
+		m.Configuration.FeatureConfig = append(m.Configuration.FeatureConfig, &FeatureConfig{})
+	}
+	config := m.Configuration.FeatureConfig[0]
+	config.Features = append(config.Features, f)
+}
+
+// AddTextureType adds a custom texture to the map.
+// If the map already has one with the same name, it is replaced.
+func (m *Map) AddTextureType(t *TextureType) {
+	for _, config := range m.Configuration.TextureConfig {
+		for i, texture := range config.Textures {
+			if texture.Name == t.Name {
+				config.Textures[i] = t
+				return
+			}
+		}
+	}
+	if len(m.Configuration.TextureConfig) == 0 {
+		m.Configuration.TextureConfig = append(m.Configuration.TextureConfig, &TextureConfig{})
+	}
+	config := m.Configuration.TextureConfig[0]
+	config.Textures = append(config.Textures, t)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import "testing"
+
+func TestAddTerrainType(t *testing.T) {
+	m := &Map{}
+	m.TerrainMap.Data = map[string]int{"Blank": 0, "Water Sea": 4}
+	m.TerrainMap.List = []*Terrain{{Index: 0, Label: "Blank"}, {Index: 4, Label: "Water Sea"}}
+
+	swamp := &TerrainType{Name: "Swamp Custom", Image: "custom/swamp.png"}
+	if got := m.AddTerrainType(swamp); got != 5 {
+		t.Errorf("index: got %d, want 5", got)
+	}
+	if len(m.Configuration.TerrainConfig) != 1 || len(m.Configuration.TerrainConfig[0].Terrains) != 1 {
+		t.Fatalf("terrain-config: expected one terrain")
+	}
+
+	// adding it again replaces the configuration but keeps the index
+	swamp2 := &TerrainType{Name: "Swamp Custom", Image: "custom/swamp-v2.png"}
+	if got := m.AddTerrainType(swamp2); got != 5 {
+		t.Errorf("index: got %d, want 5", got)
+	}
+	if terrains := m.Configuration.TerrainConfig[0].Terrains; len(terrains) != 1 || terrains[0] != swamp2 {
+		t.Errorf("terrain-config: expected the terrain to be replaced")
+	}
+	if len(m.TerrainMap.List) != 3 || m.TerrainMap.Data["Swamp Custom"] != 5 {
+		t.Errorf("terrain map: got %d terrains", len(m.TerrainMap.List))
+	}
+}
+
+func TestTerrainIndex(t *testing.T) {
+	m := &Map{}
+	m.TerrainMap.List = []*Terrain{{Index: 0, Label: "Blank"}, {Index: 4, Label: "Water Sea"}}
+
+	// the lookup table is created when the first terrain is added
+	if got := m.TerrainIndex("Flat Grazing Land"); got != 5 {
+		t.Errorf("new terrain: got %d, want 5", got)
+	}
+	if len(m.TerrainMap.List) != 3 || m.TerrainMap.Data["Flat Grazing Land"] != 5 {
+		t.Errorf("terrain map: expected the terrain to be added")
+	} else if terrain := m.TerrainMap.List[2]; terrain.Index != 5 || terrain.Label != "Flat Grazing Land" {
+		t.Errorf("terrain map: got %d %q, want 5 %q", terrain.Index, terrain.Label, "Flat Grazing Land")
+	}
+
+	// existing terrain keeps its index and isn't added again
+	m.TerrainMap.Data["Water Sea"] = 4
+	if got := m.TerrainIndex("Water Sea"); got != 4 {
+		t.Errorf("existing terrain: got %d, want 4", got)
+	}
+	if got := m.TerrainIndex("Flat Grazing Land"); got != 5 {
+		t.Errorf("added terrain: got %d, want 5", got)
+	}
+	if len(m.TerrainMap.List) != 3 {
+		t.Errorf("terrain map: got %d terrains, want 3", len(m.TerrainMap.List))
+	}
+
+	// the first terrain in an empty map gets index 0
+	if got := (&Map{}).TerrainIndex("Blank"); got != 0 {
+		t.Errorf("empty map: got %d, want 0", got)
+	}
+}
+
+func TestAddFeatureType(t *testing.T) {
+	m := &Map{}
+	tower := &FeatureType{Name: "Tower Custom", Image: "custom/tower.png"}
+	m.AddFeatureType(tower)
+	if len(m.Configuration.FeatureConfig) != 1 {
+		t.Fatalf("feature-config: got %d configs, want 1", len(m.Configuration.FeatureConfig))
+	}
+	if features := m.Configuration.FeatureConfig[0].Features; len(features) != 1 || features[0] != tower {
+		t.Fatalf("feature-config: expected the feature to be added")
+	}
+
+	// a feature with a new name is added to the first configuration
+	gate := &FeatureType{Name: "Gate Custom", Image: "custom/gate.png"}
+	m.Configuration.FeatureConfig = append(m.Configuration.FeatureConfig, &FeatureConfig{})
+	m.AddFeatureType(gate)
+	if features := m.Configuration.FeatureConfig[0].Features; len(features) != 2 || features[1] != gate {
+		t.Errorf("feature-config: expected the feature to be added to the first config")
+	}
+
+	// adding it again replaces it
+	tower2 := &FeatureType{Name: "Tower Custom", Image: "custom/tower-v2.png"}
+	m.AddFeatureType(tower2)
+	if features := m.Configuration.FeatureConfig[0].Features; len(features) != 2 || features[0] != tower2 {
+		t.Errorf("feature-config: expected the feature to be replaced")
+	}
+	if len(m.Configuration.FeatureConfig[1].Features) != 0 {
+		t.Errorf("feature-config: expected the second config to be empty")
+	}
+}
+
+func TestAddTextureType(t *testing.T) {
+	m := &Map{}
+	stone := &TextureType{Name: "Stone Custom", Image: "custom/stone.png"}
+	m.AddTextureType(stone)
+	if len(m.Configuration.TextureConfig) != 1 {
+		t.Fatalf("texture-config: got %d configs, want 1", len(m.Configuration.TextureConfig))
+	}
+	if textures := m.Configuration.TextureConfig[0].Textures; len(textures) != 1 || textures[0] != stone {
+		t.Fatalf("texture-config: expected the texture to be added")
+	}
+
+	// a texture with a new name is added to the first configuration
+	sand := &TextureType{Name: "Sand Custom", Image: "custom/sand.png"}
+	m.Configuration.TextureConfig = append(m.Configuration.TextureConfig, &TextureConfig{})
+	m.AddTextureType(sand)
+	if textures := m.Configuration.TextureConfig[0].Textures; len(textures) != 2 || textures[1] != sand {
+		t.Errorf("texture-config: expected the texture to be added to the first config")
+	}
+
+	// adding it again replaces it
+	stone2 := &TextureType{Name: "Stone Custom", Image: "custom/stone-v2.png"}
+	m.AddTextureType(stone2)
+	if textures := m.Configuration.TextureConfig[0].Textures; len(textures) != 2 || textures[0] != stone2 {
+		t.Errorf("texture-config: expected the texture to be replaced")
+	}
+	if len(m.Configuration.TextureConfig[1].Textures) != 0 {
+		t.Errorf("texture-config: expected the second config to be empty")
+	}
+}
@@ -3,6 +3,10 @@
 // Package wxx defines the types for our Worldographer interface.
 package wxx
 
+// MetaDataVersion is the version of the layout of the types in this package.
+// It is stored in MetaData.Version and checked when a Map is read from JSON.
+const MetaDataVersion = "0.0.1"
+
 // Map is the entire map.
 type Map struct {
 	MetaData struct {
@@ -72,6 +76,8 @@ type Map struct {
 		NumberPosition              string  `json:"numberPosition,omitempty"`              // "BOTTOM"
 		NumberPrePad                string  `json:"numberPrePad,omitempty"`                // "DOUBLE_ZERO"
 		NumberSeparator             string  `json:"numberSeparator,omitempty"`             // "."
+
+		Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 	} `json:"gridAndNumbering,omitempty"`
 
 	// TerrainMap assigns numbers to each terrain type.
@@ -89,7 +95,12 @@ type Map struct {
 		TilesWide int    `json:"tilesWide,omitempty"` // number of columns of tiles
 		TilesHigh int    `json:"tilesHigh,omitempty"` // number of rows of tiles
 
+		// TileRows is named for the element in the .wxx file, but each
+		// "tilerow" is a column of tiles: TileRows[column][row]. Use
+		// TileAt and SetTile rather than indexing it directly.
 		TileRows [][]*Tile `json:"tilerow,omitempty"`
+
+		Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 	} `json:"tiles,omitempty"`
 
 	MapKey MapKey `json:"mapKey,omitempty"`
@@ -105,6 +116,8 @@ type Map struct {
 	Informations struct {
 		Informations []*Information `json:"informations,omitempty"`
 		InnerText    string         `json:"innerText,omitempty"`
+
+		Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 	} `json:"informations"`
 
 	Configuration struct {
@@ -114,13 +127,44 @@ type Map struct {
 		TextConfig    struct {
 			LabelStyles []*LabelStyle `json:"labelStyles,omitempty"`
 			InnerText   string        `json:"innerText,omitempty"`
+
+			Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 		} `json:"text-config,omitempty"`
 		ShapeConfig struct {
 			ShapeStyles []*ShapeStyle `json:"shapeStyles,omitempty"`
 			InnerText   string        `json:"innerText,omitempty"`
+
+			Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 		} `json:"shape-config"`
 		InnerText string `json:"InnerText,omitempty"`
+
+		Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 	} `json:"configuration"`
+
+	// Unknown attributes and elements of the map element and of the
+	// containers that don't have a type of their own.
+	Unknown         *Unknown `json:"unknown,omitempty"`
+	UnknownFeatures *Unknown `json:"unknownFeatures,omitempty"`
+	UnknownLabels   *Unknown `json:"unknownLabels,omitempty"`
+	UnknownShapes   *Unknown `json:"unknownShapes,omitempty"`
+	UnknownNotes    *Unknown `json:"unknownNotes,omitempty"`
+}
+
+// Attr is an XML attribute that we don't model.
+type Attr struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Element is an XML element that we don't model.
+// InnerXML is the raw XML between the start and end tags.
+// Position is the number of elements that we model that came
+// before it in its parent, so that it can be written back in place.
+type Element struct {
+	Name     string `json:"name"`
+	Attrs    []Attr `json:"attrs,omitempty"`
+	InnerXML string `json:"innerXML,omitempty"`
+	Position int    `json:"position,omitempty"`
 }
 
 type Feature struct {
@@ -148,16 +192,33 @@ type Feature struct {
 
 	Location *FeatureLocation `json:"location,omitempty"`
 	Label    *Label           `json:"label,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type FeatureConfig struct {
-	InnerText string `json:"innerText,omitempty"`
+	Features  []*FeatureType `json:"features,omitempty"`
+	InnerText string         `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
+}
+
+// FeatureType is a custom feature icon.
+type FeatureType struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"` // path to the icon
+	Color *RGBA  `json:"color,omitempty"`
+	Tags  string `json:"tags,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type FeatureLocation struct {
 	ViewLevel string  `json:"viewLevel,omitempty"`
 	X         float64 `json:"x,omitempty"`
 	Y         float64 `json:"y,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type Information struct {
@@ -175,6 +236,8 @@ type Information struct {
 
 	Details   []*InformationDetail `json:"details,omitempty"`
 	InnerText string               `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type InformationDetail struct {
@@ -191,6 +254,8 @@ type InformationDetail struct {
 	Domains      string `json:"domains,omitempty"`
 
 	InnerText string `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type Label struct {
@@ -213,6 +278,8 @@ type Label struct {
 
 	Location  *LabelLocation `json:"location,omitempty"`
 	InnerText string         `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type LabelLocation struct {
@@ -220,6 +287,8 @@ type LabelLocation struct {
 	X         float64 `json:"x,omitempty"`
 	Y         float64 `json:"y,omitempty"`
 	Scale     float64 `json:"scale,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type LabelStyle struct {
@@ -232,6 +301,8 @@ type LabelStyle struct {
 	BackgroundColor *RGBA   `json:"backgroundColor,omitempty"`
 	OutlineSize     float64 `json:"outlineSize,omitempty"`
 	OutlineColor    *RGBA   `json:"outlineColor,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type MapKey struct {
@@ -259,21 +330,29 @@ type MapKey struct {
 	EntryFontBold     bool    `json:"entryFontBold,omitempty"`
 	EntryFontItalic   bool    `json:"entryFontItalic,omitempty"`
 	EntryScale        float64 `json:"entryScale,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type MapLayer struct {
 	Name      string `json:"name"`
 	IsVisible bool   `json:"isVisible"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type Note struct {
 	InnerText string `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type Point struct {
 	Type string  `json:"type,omitempty"`
 	X    float64 `json:"x,omitempty"`
 	Y    float64 `json:"y,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type RGBA struct {
@@ -326,11 +405,15 @@ type Shape struct {
 	Type                  string  `json:"type,omitempty"`
 
 	Points []*Point `json:"points,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type ShapeConfig struct {
 	ShapeStyles []*ShapeStyle `json:"shapeStyles,omitempty"`
 	InnerText   string        `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type ShapeStyle struct {
@@ -361,6 +444,8 @@ type ShapeStyle struct {
 	FillPaint     *RGBA   `json:"fillPaint,omitempty"`
 	DsColor       *RGBA   `json:"dscolor,omitempty"`
 	InsColor      *RGBA   `json:"insColor,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type Terrain struct {
@@ -369,16 +454,66 @@ type Terrain struct {
 }
 
 type TerrainConfig struct {
-	InnerText string `json:"innerText,omitempty"`
+	Terrains  []*TerrainType `json:"terrains,omitempty"`
+	InnerText string         `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
+}
+
+// TerrainType is a custom terrain.
+// To use it on tiles, it must also be added to the terrain map;
+// see Map.AddTerrainType.
+type TerrainType struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"` // path to the tile image
+	Color *RGBA  `json:"color,omitempty"`
+	Tags  string `json:"tags,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type TextConfig struct {
 	LabelStyles []*LabelStyle `json:"labelStyles,omitempty"`
 	InnerText   string        `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
 }
 
 type TextureConfig struct {
-	InnerText string `json:"innerText,omitempty"`
+	Textures  []*TextureType `json:"textures,omitempty"`
+	InnerText string         `json:"innerText,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
+}
+
+// TextureType is a custom texture for shapes.
+type TextureType struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"` // path to the texture
+	Tags  string `json:"tags,omitempty"`
+
+	Unknown *Unknown `json:"unknown,omitempty"` // attributes and elements that we don't model
+}
+
+// Unknown holds the attributes and elements of an element that we don't
+// model. They are kept so that they can be written back out unchanged.
+type Unknown struct {
+	Attrs    []Attr    `json:"attrs,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Attr returns the value of the attribute with the name.
+// Returns false if there is no such attribute.
+func (u *Unknown) Attr(name string) (string, bool) {
+	if u == nil {
+		return "", false
+	}
+	for _, attr := range u.Attrs {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
 }
 
 type Tile struct {
@@ -0,0 +1,57 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import (
+	"fmt"
+)
+
+// Hex orientations.
+// The tiles are stored column-major for both orientations;
+// the orientation only changes how the hexes are drawn.
+const (
+	OrientationColumns = "COLUMNS" // flat-topped hexes, columns are staggered
+	OrientationRows    = "ROWS"    // pointy-topped hexes, rows are staggered
+)
+
+// Width returns the number of columns of tiles.
+func (m *Map) Width() int {
+	return m.Tiles.TilesWide
+}
+
+// Height returns the number of rows of tiles.
+func (m *Map) Height() int {
+	return m.Tiles.TilesHigh
+}
+
+// TileAt returns the tile at the column and row.
+// Returns nil if the coordinates are outside the map.
+func (m *Map) TileAt(col, row int) *Tile {
+	if col < 0 || col >= m.Width() || col >= len(m.Tiles.TileRows) {
+		return nil
+	} else if row < 0 || row >= m.Height() || row >= len(m.Tiles.TileRows[col]) {
+		return nil
+	}
+	return m.Tiles.TileRows[col][row]
+}
+
+// SetTile replaces the tile at the column and row and updates the
+// tile's coordinates. Missing tiles are created as blank tiles.
+func (m *Map) SetTile(col, row int, t *Tile) error {
+	if t == nil {
+		return fmt.Errorf("tile %d,%d: missing tile", col, row)
+	} else if col < 0 || col >= m.Width() || row < 0 || row >= m.Height() {
+		return fmt.Errorf("tile %d,%d: outside of %dx%d map", col, row, m.Width(), m.Height())
+	}
+	for len(m.Tiles.TileRows) < m.Width() {
+		m.Tiles.TileRows = append(m.Tiles.TileRows, nil)
+	}
+	for c := range m.Tiles.TileRows {
+		for r := len(m.Tiles.TileRows[c]); r < m.Height(); r++ {
+			m.Tiles.TileRows[c] = append(m.Tiles.TileRows[c], &Tile{Column: c, Row: r})
+		}
+	}
+	t.Column, t.Row = col, row
+	m.Tiles.TileRows[col][row] = t
+	return nil
+}
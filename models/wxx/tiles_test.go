@@ -0,0 +1,46 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import "testing"
+
+func TestTileAt(t *testing.T) {
+	m := &Map{}
+	m.Tiles.TilesWide, m.Tiles.TilesHigh = 3, 2
+	if m.Width() != 3 || m.Height() != 2 {
+		t.Fatalf("size: got %dx%d, want 3x2", m.Width(), m.Height())
+	}
+	if tile := m.TileAt(0, 0); tile != nil {
+		t.Errorf("TileAt(0, 0): got tile before the tiles were allocated")
+	}
+
+	if err := m.SetTile(2, 1, &Tile{Terrain: 7}); err != nil {
+		t.Fatalf("SetTile(2, 1): %v", err)
+	}
+	// the tiles are stored column-major
+	if len(m.Tiles.TileRows) != 3 || len(m.Tiles.TileRows[2]) != 2 {
+		t.Fatalf("tiles: got %d columns, want 3", len(m.Tiles.TileRows))
+	}
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			tile := m.TileAt(col, row)
+			if tile == nil {
+				t.Fatalf("TileAt(%d, %d): missing tile", col, row)
+			} else if tile.Column != col || tile.Row != row {
+				t.Errorf("TileAt(%d, %d): got tile %d,%d", col, row, tile.Column, tile.Row)
+			}
+		}
+	}
+	if tile := m.TileAt(2, 1); tile.Terrain != 7 {
+		t.Errorf("TileAt(2, 1): terrain: got %d, want 7", tile.Terrain)
+	}
+
+	for _, tc := range []struct{ col, row int }{{-1, 0}, {0, -1}, {3, 0}, {0, 2}} {
+		if tile := m.TileAt(tc.col, tc.row); tile != nil {
+			t.Errorf("TileAt(%d, %d): got tile outside of map", tc.col, tc.row)
+		}
+		if err := m.SetTile(tc.col, tc.row, &Tile{}); err == nil {
+			t.Errorf("SetTile(%d, %d): expected error", tc.col, tc.row)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"context"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Options control how maps are imported and exported.
+// A nil *Options is the same as the zero value, which converts quietly
+// and can't be cancelled.
+type Options struct {
+	// Context is checked as data is read and written so that long
+	// conversions can be cancelled. If nil, context.Background() is used.
+	Context context.Context
+
+	// Logger receives warnings about the input and debug messages.
+	// If nil, nothing is logged.
+	Logger *slog.Logger
+
+	// Timing, if set, is called when each stage of a conversion completes
+	// with the name of the stage ("gzip", "utf-16", "xml", ...) and
+	// the time spent in it.
+	Timing func(stage string, elapsed time.Duration)
+
+	// DebugSink, if set, is called to create a writer for each intermediate
+	// artifact of a conversion ("input-utf-8.xml", "output-utf-16.xml", ...).
+	// The writer is closed when the conversion completes.
+	DebugSink func(name string) (io.WriteCloser, error)
+
+	// TargetVersion is the version of the .wxx file to create.
+	// If empty, the map's version is used when we can write it;
+	// otherwise, adapters.DefaultVersion is written.
+	TargetVersion string
+
+	// Transforms are applied, in order, to a map after it is imported.
+	// Imports are faithful by default; use a transform to make a
+	// deliberate edit, such as changing terrain or turning on grid numbers.
+	Transforms []func(*wxx.Map) error
+
+	// StripGM removes the GM-only content from exported maps.
+	// See StripGMOnly for the details.
+	StripGM bool
+
+	// UnknownTerrain is the terrain that GM-only tiles are changed to
+	// when StripGM is set. If empty, DefaultUnknownTerrain is used.
+	UnknownTerrain string
+}
+
+// DebugDirectory returns a DebugSink that creates the artifacts as files in a directory.
+func DebugDirectory(path string) func(name string) (io.WriteCloser, error) {
+	return func(name string) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(path, name))
+	}
+}
+
+func (o *Options) context() context.Context {
+	if o == nil || o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+func (o *Options) logger() *slog.Logger {
+	if o == nil || o.Logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return o.Logger
+}
+
+func (o *Options) timing(stage string, elapsed time.Duration) {
+	if o != nil && o.Timing != nil {
+		o.Timing(stage, elapsed)
+	}
+}
+
+// transform applies the transforms to the map.
+func (o *Options) transform(m *wxx.Map) error {
+	if o == nil {
+		return nil
+	}
+	for i, transform := range o.Transforms {
+		if err := transform(m); err != nil {
+			return fmt.Errorf("transform %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// export returns the map to export, which is a copy without the
+// GM-only content if StripGM is set.
+func (o *Options) export(m *wxx.Map) (*wxx.Map, error) {
+	if o == nil || !o.StripGM {
+		return m, nil
+	}
+	return StripGMOnly(m, o.UnknownTerrain)
+}
+
+func (o *Options) targetVersion() string {
+	if o == nil {
+		return ""
+	}
+	return o.TargetVersion
+}
+
+// debugArtifact returns a writer for the named artifact, or nil if there
+// is no debug sink. The caller must call the returned close function.
+func (o *Options) debugArtifact(name string) (io.Writer, func(), error) {
+	if o == nil || o.DebugSink == nil {
+		return nil, func() {}, nil
+	}
+	w, err := o.DebugSink(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, func() {
+		if err := w.Close(); err != nil {
+			o.logger().Error("debug artifact", "name", name, "error", err)
+		} else {
+			o.logger().Debug("debug artifact", "name", name)
+		}
+	}, nil
+}
+
+// ctxReader returns an error from Read once the context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// ctxWriter returns an error from Write once the context is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// timedReader accumulates the time spent reading from r.
+// Since the stages are chained, the time includes the stages upstream of r.
+type timedReader struct {
+	r       io.Reader
+	elapsed time.Duration
+}
+
+func (t *timedReader) Read(p []byte) (int, error) {
+	started := time.Now()
+	n, err := t.r.Read(p)
+	t.elapsed += time.Since(started)
+	return n, err
+}
+
+// timedWriter accumulates the time spent writing to w.
+// Since the stages are chained, the time includes the stages downstream of w.
+type timedWriter struct {
+	w       io.Writer
+	elapsed time.Duration
+}
+
+func (t *timedWriter) Write(p []byte) (int, error) {
+	started := time.Now()
+	n, err := t.w.Write(p)
+	t.elapsed += time.Since(started)
+	return n, err
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransforms checks that imports are faithful unless the caller
+// asks for a transform.
+func TestTransforms(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "basic.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeWXX(t, src)
+
+	m, err := Decode(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ShowGridNumbers {
+		t.Errorf("showGridNumbers: got true, want false")
+	}
+	if got := m.Tiles.TileRows[0][0].Terrain; got != 0 {
+		t.Errorf("terrain: got %d, want 0", got)
+	}
+
+	var calls []string
+	opts := &Options{Transforms: []func(*wxx.Map) error{
+		func(m *wxx.Map) error {
+			calls = append(calls, "grid")
+			m.ShowGridNumbers = true
+			return nil
+		},
+		func(m *wxx.Map) error {
+			calls = append(calls, "terrain")
+			m.Tiles.TileRows[0][0].Terrain = 1
+			return nil
+		},
+	}}
+	m, err = Decode(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "grid" || calls[1] != "terrain" {
+		t.Errorf("transforms: got %v, want [grid terrain]", calls)
+	}
+	if !m.ShowGridNumbers || m.Tiles.TileRows[0][0].Terrain != 1 {
+		t.Errorf("transforms: map was not changed")
+	}
+
+	failed := errors.New("failed")
+	opts = &Options{Transforms: []func(*wxx.Map) error{
+		func(m *wxx.Map) error { return failed },
+	}}
+	if _, err = Decode(bytes.NewReader(data), opts); !errors.Is(err, failed) {
+		t.Errorf("transform error: got %v, want %v", err, failed)
+	}
+}
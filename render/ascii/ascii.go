@@ -0,0 +1,331 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package ascii renders the tiles of a map as text for a terminal.
+//
+// Each tile is drawn as one glyph. The staggered columns (or rows) of
+// the map are drawn on alternate lines (or indented), so the text has the
+// same shape as the map:
+//
+//	.   ~   ~
+//	  T   n
+//	.   ~   ~
+//	  T   A
+package ascii
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+	"image/color"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Glyphs maps terrain labels to the glyph drawn for them.
+type Glyphs map[string]rune
+
+// defaultGlyphs are matched against the words in a terrain label when
+// the glyphs don't have the label. The first match wins.
+var defaultGlyphs = []struct {
+	word  string
+	glyph rune
+}{
+	{"blank", '.'},
+	{"unknown", '?'},
+	{"fog", '?'},
+	{"ice", '*'},
+	{"snow", '*'},
+	{"sea", '~'},
+	{"ocean", '~'},
+	{"lake", '~'},
+	{"water", '~'},
+	{"swamp", '%'},
+	{"marsh", '%'},
+	{"jungle", 'T'},
+	{"forest", 'T'},
+	{"mountain", 'A'},
+	{"hills", 'n'},
+	{"desert", ':'},
+	{"grass", '"'},
+	{"flat", '"'},
+	{"farm", '='},
+}
+
+// Glyph returns the glyph for the terrain. Labels that aren't in the
+// glyphs are matched against a list of common words ("Water", "Forest",
+// ...) and then use the first letter of the label.
+func (g Glyphs) Glyph(label string) rune {
+	if r, ok := g[label]; ok {
+		return r
+	}
+	words := strings.Fields(strings.ToLower(label))
+	for _, dg := range defaultGlyphs {
+		for _, word := range words {
+			if strings.HasPrefix(word, dg.word) {
+				return dg.glyph
+			}
+		}
+	}
+	for _, r := range strings.ToLower(label) {
+		if isNarrow(r) && !unicode.IsSpace(r) {
+			return r
+		}
+	}
+	return '?'
+}
+
+// wideRanges are the characters that terminals draw two columns wide:
+// the East Asian wide and full-width characters and most emoji.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f},   // Hangul Jamo
+	{0x231a, 0x231b},   // watch, hourglass
+	{0x2329, 0x232a},   // angle brackets
+	{0x23e9, 0x23ec},   // media controls
+	{0x23f0, 0x23f0},   // alarm clock
+	{0x23f3, 0x23f3},   // hourglass
+	{0x25fd, 0x25fe},   // small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac
+	{0x267f, 0x267f},   // wheelchair
+	{0x2693, 0x2693},   // anchor
+	{0x26a1, 0x26a1},   // high voltage
+	{0x26aa, 0x26ab},   // circles
+	{0x26bd, 0x26be},   // balls
+	{0x26c4, 0x26c5},   // snowman, sun
+	{0x26ce, 0x26ce},   // ophiuchus
+	{0x26d4, 0x26d4},   // no entry
+	{0x26ea, 0x26ea},   // church
+	{0x26f2, 0x26f3},   // fountain, golf
+	{0x26f5, 0x26f5},   // sailboat
+	{0x26fa, 0x26fa},   // tent
+	{0x26fd, 0x26fd},   // fuel pump
+	{0x2705, 0x2705},   // check mark
+	{0x270a, 0x270b},   // hands
+	{0x2728, 0x2728},   // sparkles
+	{0x274c, 0x274c},   // cross mark
+	{0x274e, 0x274e},   // cross mark
+	{0x2753, 0x2755},   // question marks
+	{0x2757, 0x2757},   // exclamation mark
+	{0x2795, 0x2797},   // plus, minus, divide
+	{0x27b0, 0x27b0},   // curly loop
+	{0x27bf, 0x27bf},   // double curly loop
+	{0x2b1b, 0x2b1c},   // large squares
+	{0x2b50, 0x2b50},   // star
+	{0x2b55, 0x2b55},   // circle
+	{0x2e80, 0x303e},   // CJK radicals and punctuation
+	{0x3041, 0x33ff},   // kana, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms
+	{0xff00, 0xff60},   // full-width forms
+	{0xffe0, 0xffe6},   // full-width signs
+	{0x16fe0, 0x18cff}, // Tangut
+	{0x1b000, 0x1b2ff}, // kana supplement
+	{0x1f004, 0x1f004}, // mahjong
+	{0x1f0cf, 0x1f0cf}, // playing card
+	{0x1f18e, 0x1f18e}, // AB button
+	{0x1f191, 0x1f19a}, // squared words
+	{0x1f200, 0x1f2ff}, // enclosed ideographs
+	{0x1f300, 0x1f64f}, // pictographs, emoticons
+	{0x1f680, 0x1f6ff}, // transport
+	{0x1f7e0, 0x1f7eb}, // colored shapes
+	{0x1f90c, 0x1f9ff}, // supplemental pictographs
+	{0x1fa70, 0x1faff}, // symbols and pictographs extended
+	{0x20000, 0x3fffd}, // CJK extensions
+}
+
+// isNarrow returns true if the character is drawn one column wide, so
+// that it can be used as a glyph without breaking the columns of the map.
+// Combining marks and format characters take no columns, and the wide
+// characters take two.
+func isNarrow(r rune) bool {
+	if !unicode.IsGraphic(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return false
+	}
+	for _, wr := range wideRanges {
+		if wr.lo <= r && r <= wr.hi {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadGlyphs reads glyphs from a JSON object that maps terrain labels to
+// a single character, e.g. {"Water Sea": "~"}. The characters must be
+// one column wide, since the map is drawn as columns of characters.
+func ReadGlyphs(r io.Reader) (Glyphs, error) {
+	var values map[string]string
+	if err := json.NewDecoder(r).Decode(&values); err != nil {
+		return nil, fmt.Errorf("glyphs: %w", err)
+	}
+	g := Glyphs{}
+	for label, value := range values {
+		if utf8.RuneCountInString(value) != 1 {
+			return nil, fmt.Errorf("glyphs: %q: %q: expected a single character", label, value)
+		}
+		g[label], _ = utf8.DecodeRuneInString(value)
+		if !isNarrow(g[label]) {
+			return nil, fmt.Errorf("glyphs: %q: %q: expected a character one column wide", label, value)
+		}
+	}
+	return g, nil
+}
+
+// Options control how a map is rendered.
+// A nil *Options is the same as the zero value, which draws the
+// whole map without colors.
+type Options struct {
+	// Glyphs is the glyph for each terrain.
+	Glyphs Glyphs
+
+	// Color draws each tile on a background of the tile's color,
+	// using 24-bit ANSI escape codes.
+	Color bool
+
+	// Palette is the terrain colors when Color is set.
+	Palette render.Palette
+
+	// At is the tile in the top-left corner of the view.
+	At hexgrid.Hex
+
+	// Cols and Rows are the size of the view in tiles.
+	// If zero, the view extends to the edge of the map.
+	Cols, Rows int
+}
+
+// Render writes the tiles of the map to w.
+func Render(w io.Writer, m *wxx.Map, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	grid, err := hexgrid.New(m)
+	if err != nil {
+		return fmt.Errorf("ascii: %w", err)
+	}
+	for label, glyph := range opts.Glyphs {
+		if !isNarrow(glyph) {
+			return fmt.Errorf("ascii: glyphs: %q: %q: expected a character one column wide", label, glyph)
+		}
+	}
+	if opts.At.Col < 0 || opts.At.Row < 0 || opts.Cols < 0 || opts.Rows < 0 {
+		return fmt.Errorf("ascii: view: invalid value")
+	} else if m.Width() > 0 && m.Height() > 0 && !grid.Contains(opts.At) {
+		return fmt.Errorf("ascii: view: %s: not on the %dx%d map", opts.At, m.Width(), m.Height())
+	}
+	cols, rows := m.Width()-opts.At.Col, m.Height()-opts.At.Row
+	if opts.Cols != 0 {
+		cols = min(cols, opts.Cols)
+	}
+	if opts.Rows != 0 {
+		rows = min(rows, opts.Rows)
+	}
+
+	r := &writer{m: m, opts: opts, grid: grid, w: bufio.NewWriter(w)}
+	for row := opts.At.Row; row < opts.At.Row+rows; row++ {
+		if grid.Orientation == hexgrid.Rows {
+			// one line per row, with the shifted rows indented
+			var line []*hexgrid.Hex
+			for col := opts.At.Col; col < opts.At.Col+cols; col++ {
+				line = append(line, &hexgrid.Hex{Col: col, Row: row})
+			}
+			if r.isShifted(row) {
+				_, _ = r.w.WriteString(" ")
+			}
+			r.line(line)
+			continue
+		}
+		// two lines per row, the shifted columns on the second line
+		for _, shifted := range []bool{false, true} {
+			var line []*hexgrid.Hex
+			for col := opts.At.Col; col < opts.At.Col+cols; col++ {
+				if r.isShifted(col) == shifted {
+					line = append(line, &hexgrid.Hex{Col: col, Row: row})
+				} else {
+					line = append(line, nil)
+				}
+			}
+			r.line(line)
+		}
+	}
+	if err = r.w.Flush(); err != nil {
+		return fmt.Errorf("ascii: %w", err)
+	}
+	return nil
+}
+
+type writer struct {
+	m    *wxx.Map
+	opts *Options
+	grid *hexgrid.Grid
+	w    *bufio.Writer // errors are sticky and reported by Flush
+}
+
+// isShifted returns true if the column (or row) is shifted by half a hex.
+func (r *writer) isShifted(n int) bool {
+	if r.grid.Offset == hexgrid.Even {
+		return n%2 == 0
+	}
+	return n%2 == 1
+}
+
+// line writes the tiles, two characters per tile. A nil tile is a gap.
+// Trailing gaps are not written.
+func (r *writer) line(tiles []*hexgrid.Hex) {
+	for len(tiles) != 0 && tiles[len(tiles)-1] == nil {
+		tiles = tiles[:len(tiles)-1]
+	}
+	for i, h := range tiles {
+		if i != 0 {
+			_, _ = r.w.WriteString(" ")
+		}
+		if h == nil {
+			_, _ = r.w.WriteString(" ")
+			continue
+		}
+		r.tile(r.m.TileAt(h.Col, h.Row))
+	}
+	_, _ = r.w.WriteString("\n")
+}
+
+// tile writes the glyph for the tile. GM-only tiles are left blank
+// unless the map shows GM-only content.
+func (r *writer) tile(t *wxx.Tile) {
+	if t == nil || (t.IsGMOnly && !r.m.ShowGMOnly) {
+		_, _ = r.w.WriteString(" ")
+		return
+	}
+	var label string
+	for _, terrain := range r.m.TerrainMap.List {
+		if terrain.Index == t.Terrain {
+			label = terrain.Label
+			break
+		}
+	}
+	glyph := r.opts.Glyphs.Glyph(label)
+	if !r.opts.Color {
+		_, _ = r.w.WriteRune(glyph)
+		return
+	}
+	bg := render.TileColor(r.m, t, r.opts.Palette)
+	fg := contrast(bg)
+	_, _ = fmt.Fprintf(r.w, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm%c\x1b[0m", fg.R, fg.G, fg.B, bg.R, bg.G, bg.B, glyph)
+}
+
+// contrast returns black or white, whichever is easier to read on c.
+func contrast(c color.NRGBA) color.NRGBA {
+	luma := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+	if luma > 140 {
+		return color.NRGBA{A: 0xff}
+	}
+	return color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package ascii
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		opts  *Options
+		want  string
+	}{
+		{input: "basic.xml", want: ".\n  \"\n\"\n  .\n"},
+		{input: "rows.xml", want: ". \" ~\n ~ T \"\n"},
+		{input: "rows.xml", opts: &Options{At: hexgrid.Hex{Col: 1, Row: 1}, Cols: 5, Rows: 5}, want: " T \"\n"},
+		{input: "rows.xml", opts: &Options{Cols: 2, Rows: 1, Glyphs: Glyphs{"Blank": '_'}}, want: "_ \"\n"},
+	} {
+		m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", tc.input))
+		var out bytes.Buffer
+		if err := Render(&out, m, tc.opts); err != nil {
+			t.Errorf("%s: %v", tc.input, err)
+		} else if got := out.String(); got != tc.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestRenderGMOnly(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "rows.xml"))
+	m.TileAt(1, 0).IsGMOnly = true
+	for _, tc := range []struct {
+		showGMOnly bool
+		want       string
+	}{
+		{showGMOnly: false, want: ".   ~\n ~ T \"\n"},
+		{showGMOnly: true, want: ". \" ~\n ~ T \"\n"},
+	} {
+		m.ShowGMOnly = tc.showGMOnly
+		var out bytes.Buffer
+		if err := Render(&out, m, nil); err != nil {
+			t.Fatal(err)
+		} else if got := out.String(); got != tc.want {
+			t.Errorf("showGMOnly %v: got\n%s\nwant\n%s", tc.showGMOnly, got, tc.want)
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "rows.xml"))
+	var out bytes.Buffer
+	if err := Render(&out, m, &Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(out.String(), "\x1b[0m"), m.Width()*m.Height(); got != want {
+		t.Errorf("got %d colored tiles, want %d", got, want)
+	}
+}
+
+func TestRenderView(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "rows.xml"))
+	for _, opts := range []*Options{
+		{At: hexgrid.Hex{Col: 3, Row: 0}},
+		{At: hexgrid.Hex{Col: 0, Row: -1}},
+		{Cols: -1},
+	} {
+		if err := Render(&bytes.Buffer{}, m, opts); err == nil {
+			t.Errorf("%+v: want error, got nil", *opts)
+		}
+	}
+}
+
+func TestReadGlyphs(t *testing.T) {
+	glyphs, err := ReadGlyphs(strings.NewReader(`{"Water Sea": "≈"}`))
+	if err != nil {
+		t.Fatal(err)
+	} else if got := glyphs.Glyph("Water Sea"); got != '≈' {
+		t.Errorf("Water Sea: got %q, want %q", got, '≈')
+	}
+	for name, value := range map[string]string{
+		"two characters":     `"~~"`,
+		"wide character":     `"海"`,
+		"emoji":              `"🌊"`,
+		"combining sequence": `"e\u0301"`,
+		"combining mark":     `"\u0301"`,
+		"control character":  `"\t"`,
+	} {
+		if _, err := ReadGlyphs(strings.NewReader(`{"Water Sea": ` + value + `}`)); err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+
+	// glyphs that aren't read from a file are checked, too
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "rows.xml"))
+	if err := Render(&bytes.Buffer{}, m, &Options{Glyphs: Glyphs{"Blank": '海'}}); err == nil {
+		t.Errorf("render wide glyph: want error, got nil")
+	}
+	// a wide first letter isn't used for terrain without a glyph
+	if got := (Glyphs{}).Glyph("海 Deep"); got != 'd' {
+		t.Errorf("wide label: got %q, want %q", got, 'd')
+	}
+}
+
+func loadMap(t *testing.T, path string) *wxx.Map {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wxml, err := adapters.UTF8ToWXML(data)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	m, err := adapters.WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return m
+}
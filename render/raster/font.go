@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package raster
+
+// glyphs is a 3x5 bitmap font with the characters that can appear in a
+// grid number. Each row is 3 bits, with the left-most pixel in bit 2.
+var glyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'.': {0, 0, 0, 0, 2},
+	',': {0, 0, 0, 2, 4},
+	'-': {0, 0, 7, 0, 0},
+	':': {0, 2, 0, 2, 0},
+	'/': {1, 1, 2, 4, 4},
+	' ': {0, 0, 0, 0, 0},
+}
+
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphAdvance = glyphWidth + 1 // one pixel between characters
+)
+
+// textWidth returns the width of the text in font pixels.
+// Characters that aren't in the font are skipped.
+func textWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if _, ok := glyphs[r]; ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return n*glyphAdvance - 1
+}
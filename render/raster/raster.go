@@ -0,0 +1,298 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package raster renders a map as a PNG image using only the standard
+// library. It draws the tiles, the grid and the grid numbers; use the
+// svg package for features, labels and shapes.
+package raster
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// maxSize is the largest width or height of an image, to keep a typo
+// in the width from using all the memory.
+const maxSize = 16384
+
+// Options control how a map is rendered.
+// A nil *Options is the same as the zero value.
+type Options struct {
+	// Palette is the terrain colors.
+	// Terrain that isn't in the palette gets a default color.
+	Palette render.Palette
+
+	// Width is the width of the image in pixels. The height is set to
+	// keep the map's proportions. If zero, the map's pixel size is used.
+	Width int
+}
+
+// Render writes the map to w as a PNG image.
+func Render(w io.Writer, m *wxx.Map, opts *Options) error {
+	img, err := Draw(m, opts)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(w, img); err != nil {
+		return fmt.Errorf("png: %w", err)
+	}
+	return nil
+}
+
+// Draw returns the map as an image.
+func Draw(m *wxx.Map, opts *Options) (*image.NRGBA, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		return nil, fmt.Errorf("png: %w", err)
+	}
+	mapWidth, mapHeight := render.Size(m, layout)
+	scale := 1.0
+	if opts.Width < 0 {
+		return nil, fmt.Errorf("png: width: invalid value %d", opts.Width)
+	} else if opts.Width != 0 && mapWidth > 0 {
+		scale = float64(opts.Width) / mapWidth
+	}
+	width, height := int(math.Ceil(mapWidth*scale)), int(math.Ceil(mapHeight*scale))
+	if width > maxSize || height > maxSize {
+		return nil, fmt.Errorf("png: %dx%d: larger than %dx%d", width, height, maxSize, maxSize)
+	}
+
+	r := &rasterizer{
+		m:      m,
+		layout: layout,
+		scale:  scale,
+		img:    image.NewNRGBA(image.Rect(0, 0, width, height)),
+	}
+	r.tiles(opts.Palette)
+	if m.ShowGrid && isGridVisible(m) {
+		r.grid()
+		if m.ShowGridNumbers {
+			r.gridNumbers()
+		}
+	}
+	return r.img, nil
+}
+
+// isGridVisible returns true if the grid layer is visible.
+// Maps that don't list their layers always show the grid.
+func isGridVisible(m *wxx.Map) bool {
+	if !render.IsKnownLayer(m, "Grid") {
+		return true
+	}
+	for _, layer := range render.Layers(m) {
+		if layer == "Grid" {
+			return true
+		}
+	}
+	return false
+}
+
+type rasterizer struct {
+	m      *wxx.Map
+	layout *hexgrid.Layout
+	scale  float64 // image pixels per map pixel
+	img    *image.NRGBA
+}
+
+// corners returns the corners of the hex in image pixels.
+func (r *rasterizer) corners(h hexgrid.Hex) [6]hexgrid.Point {
+	corners := r.layout.Corners(h)
+	for i := range corners {
+		corners[i].X, corners[i].Y = corners[i].X*r.scale, corners[i].Y*r.scale
+	}
+	return corners
+}
+
+// tiles fills every tile with the color of its terrain. Tiles are skipped
+// if their terrain layer, "Terrain Land" or "Terrain Water", is hidden.
+func (r *rasterizer) tiles(palette render.Palette) {
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			t := r.m.TileAt(col, row)
+			if t == nil || (t.IsGMOnly && !r.m.ShowGMOnly) {
+				continue
+			} else if !render.IsVisibleLayer(r.m, render.TerrainLayer(r.m, t)) {
+				continue
+			}
+			corners := r.corners(hexgrid.Hex{Col: col, Row: row})
+			fillPolygon(r.img, corners[:], render.TileColor(r.m, t, palette))
+		}
+	}
+}
+
+// grid draws the outline of every tile. The lines are drawn into a mask
+// first so that the edges shared by two tiles aren't blended twice.
+func (r *rasterizer) grid() {
+	g := r.m.GridAndNumbering
+	c, err := render.ParseColor(g.Color0)
+	if err != nil {
+		c = color.NRGBA{A: 0x40}
+	}
+	width := g.Width0
+	if width <= 0 {
+		width = 1
+	}
+	width = math.Max(1, width*r.scale)
+
+	mask := image.NewAlpha(r.img.Bounds())
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			corners := r.corners(hexgrid.Hex{Col: col, Row: row})
+			for i := range corners {
+				strokeLine(mask, corners[i], corners[(i+1)%len(corners)], width)
+			}
+		}
+	}
+	draw.DrawMask(r.img, r.img.Bounds(), image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// gridNumbers labels every tile with its grid number.
+func (r *rasterizer) gridNumbers() {
+	numbering, err := hexgrid.NewNumbering(r.m)
+	if err != nil {
+		return // Worldographer doesn't draw numbers it can't format either
+	}
+	c, err := render.ParseColor(r.m.GridAndNumbering.NumberColor)
+	if err != nil {
+		c = color.NRGBA{A: 0xff}
+	}
+	// size the font from the height of the digits, but keep the widest
+	// number inside its hex
+	widest := 0
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			widest = max(widest, textWidth(numbering.Format(hexgrid.Hex{Col: col, Row: row})))
+		}
+	}
+	pixel := int(math.Round(render.GridNumberSize(r.m) * r.scale * 0.7 / glyphHeight))
+	if widest > 0 {
+		pixel = min(pixel, int(r.layout.HexWidth*r.scale*0.6)/widest)
+	}
+	if pixel < 1 {
+		return // too small to read
+	}
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			h := hexgrid.Hex{Col: col, Row: row}
+			p := render.GridNumberPosition(r.m, r.layout, h)
+			drawText(r.img, numbering.Format(h), p.X*r.scale, p.Y*r.scale, pixel, c)
+		}
+	}
+}
+
+// fillPolygon fills the polygon with the color. A pixel is filled if
+// its center is inside, so tiles that share an edge don't overlap or
+// leave gaps.
+func fillPolygon(img *image.NRGBA, pts []hexgrid.Point, c color.NRGBA) {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	bounds := img.Bounds()
+	y0, y1 := max(bounds.Min.Y, int(math.Floor(minY))), min(bounds.Max.Y, int(math.Ceil(maxY)))
+	for y := y0; y < y1; y++ {
+		cy := float64(y) + 0.5
+		left, right := math.Inf(1), math.Inf(-1)
+		for i, a := range pts {
+			b := pts[(i+1)%len(pts)]
+			if (a.Y <= cy) == (b.Y <= cy) {
+				continue // the edge doesn't cross this row
+			}
+			x := a.X + (cy-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			left, right = math.Min(left, x), math.Max(right, x)
+		}
+		if left > right {
+			continue
+		}
+		// pixels whose centers are in [left, right)
+		x0 := max(bounds.Min.X, int(math.Ceil(left-0.5)))
+		x1 := min(bounds.Max.X, int(math.Ceil(right-0.5)))
+		for x := x0; x < x1; x++ {
+			blend(img, x, y, c)
+		}
+	}
+}
+
+// strokeLine adds an anti-aliased line of the given width to the mask.
+func strokeLine(mask *image.Alpha, a, b hexgrid.Point, width float64) {
+	half := width / 2
+	bounds := mask.Bounds()
+	x0 := max(bounds.Min.X, int(math.Floor(math.Min(a.X, b.X)-half-1)))
+	x1 := min(bounds.Max.X, int(math.Ceil(math.Max(a.X, b.X)+half+1)))
+	y0 := max(bounds.Min.Y, int(math.Floor(math.Min(a.Y, b.Y)-half-1)))
+	y1 := min(bounds.Max.Y, int(math.Ceil(math.Max(a.Y, b.Y)+half+1)))
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length2 := dx*dx + dy*dy
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			t := 0.0
+			if length2 > 0 {
+				t = math.Max(0, math.Min(1, ((px-a.X)*dx+(py-a.Y)*dy)/length2))
+			}
+			d := math.Hypot(px-(a.X+t*dx), py-(a.Y+t*dy))
+			coverage := math.Max(0, math.Min(1, half+0.5-d))
+			if v := uint8(coverage * 255); v > mask.AlphaAt(x, y).A {
+				mask.SetAlpha(x, y, color.Alpha{A: v})
+			}
+		}
+	}
+}
+
+// drawText draws the text centered on x, y. Each font pixel is a square
+// of pixel by pixel image pixels.
+func drawText(img *image.NRGBA, s string, x, y float64, pixel int, c color.NRGBA) {
+	left := int(math.Round(x)) - textWidth(s)*pixel/2
+	top := int(math.Round(y)) - glyphHeight*pixel/2
+	for _, ch := range s {
+		glyph, ok := glyphs[ch]
+		if !ok {
+			continue
+		}
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				for py := 0; py < pixel; py++ {
+					for px := 0; px < pixel; px++ {
+						blend(img, left+col*pixel+px, top+row*pixel+py, c)
+					}
+				}
+			}
+		}
+		left += glyphAdvance * pixel
+	}
+}
+
+// blend draws the color over the pixel.
+func blend(img *image.NRGBA, x, y int, c color.NRGBA) {
+	if !(image.Point{X: x, Y: y}.In(img.Bounds())) {
+		return
+	}
+	if c.A == 0xff {
+		img.SetNRGBA(x, y, c)
+		return
+	}
+	dst := img.NRGBAAt(x, y)
+	a := float64(c.A) / 255
+	da := float64(dst.A) / 255
+	oa := a + da*(1-a)
+	if oa == 0 {
+		return
+	}
+	mix := func(s, d uint8) uint8 {
+		return uint8(math.Round((float64(s)*a + float64(d)*da*(1-a)) / oa))
+	}
+	img.SetNRGBA(x, y, color.NRGBA{R: mix(c.R, dst.R), G: mix(c.G, dst.G), B: mix(c.B, dst.B), A: uint8(math.Round(oa * 255))})
+}
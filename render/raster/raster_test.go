@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package raster
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDraw(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "basic.xml"))
+	palette := render.Palette{"Blank": color.NRGBA{R: 0xff, A: 0xff}, "Flat Grazing Land": color.NRGBA{G: 0xff, A: 0xff}}
+	img, err := Draw(m, &Options{Palette: palette, Width: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Dx(); got != 400 {
+		t.Errorf("width: got %d, want 400", got)
+	}
+
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapWidth, _ := render.Size(m, layout)
+	scale := 400 / mapWidth
+	for _, tc := range []struct {
+		hex  hexgrid.Hex
+		want color.NRGBA
+	}{
+		{hexgrid.Hex{Col: 0, Row: 0}, palette["Blank"]},
+		{hexgrid.Hex{Col: 0, Row: 1}, palette["Flat Grazing Land"]},
+		{hexgrid.Hex{Col: 1, Row: 0}, palette["Flat Grazing Land"]},
+	} {
+		c := layout.Center(tc.hex)
+		x, y := int(c.X*scale), int(c.Y*scale)
+		if got := img.NRGBAAt(x, y); got != tc.want {
+			t.Errorf("%s: center %d,%d: got %v, want %v", tc.hex, x, y, got, tc.want)
+		}
+	}
+
+	// the grid darkens the edges between tiles
+	corners := layout.Corners(hexgrid.Hex{Col: 0, Row: 0})
+	x, y := int((corners[0].X+corners[1].X)/2*scale), int((corners[0].Y+corners[1].Y)/2*scale)
+	if got := img.NRGBAAt(x, y); got == palette["Blank"] || got == palette["Flat Grazing Land"] {
+		t.Errorf("edge %d,%d: got %v, expected the grid color", x, y, got)
+	}
+
+	var out bytes.Buffer
+	m.ShowGridNumbers = true
+	if err = Render(&out, m, &Options{Width: 400}); err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := png.Decode(&out); err != nil {
+		t.Fatalf("png: %v", err)
+	} else if decoded.Bounds() != img.Bounds() {
+		t.Errorf("png: got %v, want %v", decoded.Bounds(), img.Bounds())
+	}
+
+	if _, err = Draw(m, &Options{Width: -1}); err == nil {
+		t.Errorf("negative width: expected error")
+	}
+	if _, err = Draw(m, &Options{Width: maxSize + 1}); err == nil {
+		t.Errorf("huge width: expected error")
+	}
+}
+
+func TestDrawTerrainLayers(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "basic.xml"))
+	m.ShowGrid = false
+	m.TileAt(0, 0).Terrain = m.TerrainIndex("Water Sea")
+	palette := render.Palette{"Water Sea": color.NRGBA{B: 0xff, A: 0xff}, "Flat Grazing Land": color.NRGBA{G: 0xff, A: 0xff}}
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	water, land := layout.Center(hexgrid.Hex{Col: 0, Row: 0}), layout.Center(hexgrid.Hex{Col: 0, Row: 1})
+
+	for _, tc := range []struct {
+		hide        string
+		water, land color.NRGBA
+	}{
+		{hide: "", water: palette["Water Sea"], land: palette["Flat Grazing Land"]},
+		{hide: "Terrain Water", water: color.NRGBA{}, land: palette["Flat Grazing Land"]},
+		{hide: "Terrain Land", water: palette["Water Sea"], land: color.NRGBA{}},
+	} {
+		for i := range m.MapLayer {
+			m.MapLayer[i].IsVisible = m.MapLayer[i].Name != tc.hide
+		}
+		img, err := Draw(m, &Options{Palette: palette})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.NRGBAAt(int(water.X), int(water.Y)); got != tc.water {
+			t.Errorf("hide %q: water: got %v, want %v", tc.hide, got, tc.water)
+		}
+		if got := img.NRGBAAt(int(land.X), int(land.Y)); got != tc.land {
+			t.Errorf("hide %q: land: got %v, want %v", tc.hide, got, tc.land)
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"0", 3},
+		{"04.12", 19},
+		{"x", 0},
+	} {
+		if got := textWidth(tc.s); got != tc.want {
+			t.Errorf("%q: got %d, want %d", tc.s, got, tc.want)
+		}
+	}
+}
+
+// loadMap imports a UTF-8 test map.
+func loadMap(t *testing.T, path string) *wxx.Map {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wxml, err := adapters.UTF8ToWXML(data)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	m, err := adapters.WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return m
+}
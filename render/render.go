@@ -0,0 +1,219 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package render has the helpers that the renderers share:
+// terrain colors, color parsing, layer visibility and view levels.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Palette maps terrain labels to colors.
+type Palette map[string]color.NRGBA
+
+// defaultColors are matched against the words in a terrain label when
+// the palette doesn't have the label. The first match wins, so the more
+// specific words come first.
+var defaultColors = []struct {
+	word  string
+	color color.NRGBA
+}{
+	{"blank", color.NRGBA{R: 0xf2, G: 0xf2, B: 0xf2, A: 0xff}},
+	{"unknown", color.NRGBA{R: 0xbf, G: 0xbf, B: 0xbf, A: 0xff}},
+	{"fog", color.NRGBA{R: 0xbf, G: 0xbf, B: 0xbf, A: 0xff}},
+	{"ice", color.NRGBA{R: 0xe8, G: 0xf4, B: 0xf8, A: 0xff}},
+	{"snow", color.NRGBA{R: 0xf8, G: 0xf8, B: 0xff, A: 0xff}},
+	{"lava", color.NRGBA{R: 0xc0, G: 0x39, B: 0x2b, A: 0xff}},
+	{"sea", color.NRGBA{R: 0x1f, G: 0x5f, B: 0xa8, A: 0xff}},
+	{"ocean", color.NRGBA{R: 0x1a, G: 0x4f, B: 0x8f, A: 0xff}},
+	{"lake", color.NRGBA{R: 0x3a, G: 0x7b, B: 0xc8, A: 0xff}},
+	{"water", color.NRGBA{R: 0x2e, G: 0x6f, B: 0xb8, A: 0xff}},
+	{"swamp", color.NRGBA{R: 0x4f, G: 0x6b, B: 0x3a, A: 0xff}},
+	{"marsh", color.NRGBA{R: 0x5f, G: 0x7b, B: 0x4a, A: 0xff}},
+	{"jungle", color.NRGBA{R: 0x1e, G: 0x6b, B: 0x2e, A: 0xff}},
+	{"forest", color.NRGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}},
+	{"mountain", color.NRGBA{R: 0x7a, G: 0x6a, B: 0x5a, A: 0xff}},
+	{"hills", color.NRGBA{R: 0xa8, G: 0x9a, B: 0x5c, A: 0xff}},
+	{"desert", color.NRGBA{R: 0xe6, G: 0xcc, B: 0x80, A: 0xff}},
+	{"badlands", color.NRGBA{R: 0xb5, G: 0x7a, B: 0x4a, A: 0xff}},
+	{"tundra", color.NRGBA{R: 0xb8, G: 0xc4, B: 0xb0, A: 0xff}},
+	{"grass", color.NRGBA{R: 0x8b, G: 0xc3, B: 0x4a, A: 0xff}},
+	{"flat", color.NRGBA{R: 0xa5, G: 0xc8, B: 0x6a, A: 0xff}},
+	{"farm", color.NRGBA{R: 0xc5, G: 0xd8, B: 0x6d, A: 0xff}},
+}
+
+// defaultColor is used for terrain that doesn't match anything.
+var defaultColor = color.NRGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}
+
+// Color returns the color for the terrain. Labels that aren't in the
+// palette are matched against a list of common words ("Water", "Forest",
+// "Hills", ...), so that most maps render without a palette.
+func (p Palette) Color(label string) color.NRGBA {
+	if c, ok := p[label]; ok {
+		return c
+	}
+	words := strings.Fields(strings.ToLower(label))
+	for _, dc := range defaultColors {
+		for _, word := range words {
+			if strings.HasPrefix(word, dc.word) {
+				return dc.color
+			}
+		}
+	}
+	return defaultColor
+}
+
+// ReadPalette reads a palette from a JSON object that maps terrain labels
+// to colors, e.g. {"Water Sea": "#1f5fa8"}. See ParseColor for the formats.
+func ReadPalette(r io.Reader) (Palette, error) {
+	var colors map[string]string
+	if err := json.NewDecoder(r).Decode(&colors); err != nil {
+		return nil, fmt.Errorf("palette: %w", err)
+	}
+	p := Palette{}
+	for label, value := range colors {
+		c, err := ParseColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("palette: %q: %w", label, err)
+		}
+		p[label] = c
+	}
+	return p, nil
+}
+
+// ParseColor parses a color as "#rrggbb", "#rrggbbaa", "0xrrggbbaa"
+// (the format of the grid colors), or "r,g,b,a" with values from 0 to 1
+// (the format of the colors in the .wxx file).
+func ParseColor(s string) (color.NRGBA, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ",") {
+		fields := strings.Split(s, ",")
+		if len(fields) != 4 {
+			return color.NRGBA{}, fmt.Errorf("%q: expected r,g,b,a", s)
+		}
+		var v [4]uint8
+		for i, field := range fields {
+			f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || math.IsNaN(f) || f < 0 || f > 1 {
+				return color.NRGBA{}, fmt.Errorf("%q: invalid color", s)
+			}
+			v[i] = uint8(math.Round(f * 255))
+		}
+		return color.NRGBA{R: v[0], G: v[1], B: v[2], A: v[3]}, nil
+	}
+
+	var hex string
+	if strings.HasPrefix(s, "#") {
+		hex = s[1:]
+	} else if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		hex = s[2:]
+	} else {
+		return color.NRGBA{}, fmt.Errorf("%q: invalid color", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("%q: invalid color", s)
+	}
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// FromRGBA converts a color from the map. Returns false if there is no color.
+func FromRGBA(c *wxx.RGBA) (color.NRGBA, bool) {
+	if c == nil {
+		return color.NRGBA{}, false
+	}
+	to8 := func(f float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, f)) * 255))
+	}
+	return color.NRGBA{R: to8(c.R), G: to8(c.G), B: to8(c.B), A: to8(c.A)}, true
+}
+
+// TileColor returns the color to fill the tile with. The tile's custom
+// background color is used if it has one.
+func TileColor(m *wxx.Map, t *wxx.Tile, p Palette) color.NRGBA {
+	if c, ok := FromRGBA(t.CustomBackgroundColor); ok {
+		return c
+	}
+	for _, terrain := range m.TerrainMap.List {
+		if terrain.Index == t.Terrain {
+			return p.Color(terrain.Label)
+		}
+	}
+	return defaultColor
+}
+
+// TerrainLayer returns the name of the map layer that the tile's terrain
+// is drawn on. Water terrain ("Water Sea", "Water Lake", ...) is on the
+// "Terrain Water" layer and all other terrain is on the "Terrain Land" layer.
+func TerrainLayer(m *wxx.Map, t *wxx.Tile) string {
+	for _, terrain := range m.TerrainMap.List {
+		if terrain.Index == t.Terrain {
+			if word, _, _ := strings.Cut(terrain.Label, " "); strings.EqualFold(word, "water") {
+				return "Terrain Water"
+			}
+			break
+		}
+	}
+	return "Terrain Land"
+}
+
+// Layers returns the names of the map layers that are visible, from the
+// bottom of the map to the top. Worldographer lists the layers from the
+// top down.
+func Layers(m *wxx.Map) []string {
+	var layers []string
+	for i := len(m.MapLayer) - 1; i >= 0; i-- {
+		if m.MapLayer[i].IsVisible {
+			layers = append(layers, m.MapLayer[i].Name)
+		}
+	}
+	return layers
+}
+
+// IsKnownLayer returns true if the map has a layer with the name,
+// whether or not it is visible.
+func IsKnownLayer(m *wxx.Map, name string) bool {
+	for _, layer := range m.MapLayer {
+		if layer.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVisibleLayer returns true if the layer is visible. Layers that the
+// map doesn't have are visible.
+func IsVisibleLayer(m *wxx.Map, name string) bool {
+	for _, layer := range m.MapLayer {
+		if layer.Name == name {
+			return layer.IsVisible
+		}
+	}
+	return true
+}
+
+// AtViewLevel returns true if an item with the flags is shown at the
+// view level. Unknown view levels show everything.
+func AtViewLevel(viewLevel string, world, continent, kingdom, province bool) bool {
+	switch viewLevel {
+	case "WORLD":
+		return world
+	case "CONTINENT":
+		return continent
+	case "KINGDOM":
+		return kingdom
+	case "PROVINCE":
+		return province
+	}
+	return true
+}
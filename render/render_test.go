@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package render
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  color.NRGBA
+	}{
+		{"#1f5fa8", color.NRGBA{R: 0x1f, G: 0x5f, B: 0xa8, A: 0xff}},
+		{"#1f5fa880", color.NRGBA{R: 0x1f, G: 0x5f, B: 0xa8, A: 0x80}},
+		{"0x00000040", color.NRGBA{A: 0x40}},
+		{"1.0,0.5,0.0,1.0", color.NRGBA{R: 0xff, G: 0x80, A: 0xff}},
+	} {
+		if got, err := ParseColor(tc.input); err != nil {
+			t.Errorf("%q: %v", tc.input, err)
+		} else if got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.input, got, tc.want)
+		}
+	}
+	for _, input := range []string{"", "null", "red", "#12345", "0x1234567890", "1.0,0.5,0.0", "2.0,0,0,1", "a,b,c,d"} {
+		if _, err := ParseColor(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestPalette(t *testing.T) {
+	p, err := ReadPalette(strings.NewReader(`{"Water Sea": "#000001"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Color("Water Sea"); got != (color.NRGBA{B: 1, A: 0xff}) {
+		t.Errorf("Water Sea: got %v", got)
+	}
+	if p.Color("Water Lake") == defaultColor || p.Color("Hills Forest Mixed") == defaultColor {
+		t.Errorf("common terrain should not get the default color")
+	}
+	if got := p.Color("Xyzzy"); got != defaultColor {
+		t.Errorf("Xyzzy: got %v, want the default color", got)
+	}
+	if _, err = ReadPalette(strings.NewReader(`{"Water Sea": "blue"}`)); err == nil {
+		t.Errorf("invalid color: expected error")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package render
+
+import (
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Size returns the width and height of the map in pixels.
+func Size(m *wxx.Map, layout *hexgrid.Layout) (width, height float64) {
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			for _, p := range layout.Corners(hexgrid.Hex{Col: col, Row: row}) {
+				width, height = math.Max(width, p.X), math.Max(height, p.Y)
+			}
+		}
+	}
+	return math.Ceil(width), math.Ceil(height)
+}
+
+// GridNumberSize returns the font size of the grid numbers.
+// The map's number size is a percentage of the hex height.
+func GridNumberSize(m *wxx.Map) float64 {
+	size := float64(m.GridAndNumbering.NumberSize)
+	if size <= 0 {
+		size = 20
+	}
+	return m.HexHeight * size / 100
+}
+
+// GridNumberPosition returns the center of the grid number for the hex.
+// The number is placed near the top or the bottom of the hex.
+func GridNumberPosition(m *wxx.Map, layout *hexgrid.Layout, h hexgrid.Hex) hexgrid.Point {
+	p := layout.Center(h)
+	offset := m.HexHeight/2 - GridNumberSize(m)*0.75
+	switch m.GridAndNumbering.NumberPosition {
+	case "TOP":
+		p.Y -= offset
+	case "CENTER", "MIDDLE":
+	default: // "BOTTOM"
+		p.Y += offset
+	}
+	return p
+}
+
+// StrokeWidth returns the width of the shape's outline in pixels.
+// The map stores the width as a fraction of the hex height.
+func StrokeWidth(m *wxx.Map, s *wxx.Shape) float64 {
+	return s.StrokeWidth * m.HexHeight
+}
+
+// ShapeFill returns the color to fill the shape with. Returns false if
+// the shape's fill paint is missing or "null".
+func ShapeFill(s *wxx.Shape) (color.NRGBA, bool) {
+	value, ok := s.Unknown.Attr("fillPaint")
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	c, err := ParseColor(value)
+	return c, err == nil
+}
+
+// FillRule converts the shape's fill rule to its SVG name.
+func FillRule(rule string) string {
+	switch rule {
+	case "EVEN_ODD":
+		return "evenodd"
+	case "NON_ZERO":
+		return "nonzero"
+	}
+	return ""
+}
+
+// LabelSize returns the font size of the label in pixels.
+// The label's scale is its font size; the default is 12.
+func LabelSize(l *wxx.Label) float64 {
+	if l.Location == nil || l.Location.Scale <= 0 {
+		return 12
+	}
+	return l.Location.Scale
+}
+
+// The default colors for feature symbols.
+var (
+	FeatureColor     = color.NRGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	FeatureRingColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// FeatureSize returns the radius of a feature symbol in pixels.
+func FeatureSize(m *wxx.Map) float64 {
+	return m.HexHeight / 5
+}
+
+// Symbol is the mark drawn for a feature.
+type Symbol int
+
+const (
+	Circle Symbol = iota
+	Square
+	Triangle
+)
+
+// SymbolFor returns the symbol for a feature type, e.g. a square for
+// "Settlement City" and a triangle for "Dungeon".
+func SymbolFor(featureType string) Symbol {
+	t := strings.ToLower(featureType)
+	for _, word := range []string{"settlement", "city", "town", "village", "castle", "fort", "keep"} {
+		if strings.Contains(t, word) {
+			return Square
+		}
+	}
+	for _, word := range []string{"dungeon", "cave", "ruin", "tower", "mountain", "peak", "volcano"} {
+		if strings.Contains(t, word) {
+			return Triangle
+		}
+	}
+	return Circle
+}
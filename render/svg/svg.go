@@ -0,0 +1,362 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package svg renders a map as an SVG document.
+//
+// The document uses the map's pixel space, so features, labels and shapes
+// are placed where Worldographer places them. Tiles on a visible terrain
+// layer, "Terrain Land" or "Terrain Water", are drawn first,
+// followed by the items on each visible map layer from the bottom up.
+// Items on layers that the map doesn't list are drawn on top.
+package svg
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Options control how a map is rendered.
+// A nil *Options is the same as the zero value.
+type Options struct {
+	// Palette is the terrain colors.
+	// Terrain that isn't in the palette gets a default color.
+	Palette render.Palette
+
+	// Scale is the size of the image relative to the map's pixel space.
+	// If zero, the image is the same size.
+	Scale float64
+}
+
+// Render writes the map to w as an SVG document.
+func Render(w io.Writer, m *wxx.Map, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		return fmt.Errorf("svg: %w", err)
+	}
+	scale := opts.Scale
+	if scale == 0 {
+		scale = 1
+	} else if scale < 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return fmt.Errorf("svg: scale: invalid value %v", opts.Scale)
+	}
+
+	r := &renderer{
+		m:       m,
+		layout:  layout,
+		palette: opts.Palette,
+		w:       bufio.NewWriter(w),
+	}
+
+	width, height := render.Size(m, layout)
+	r.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n", num(width*scale), num(height*scale), num(width), num(height))
+	r.tiles()
+	for _, layer := range render.Layers(m) {
+		r.layer(layer, func(name string) bool { return name == layer })
+	}
+	r.layer("", func(name string) bool { return !render.IsKnownLayer(m, name) })
+	r.printf("</svg>\n")
+
+	if err = r.w.Flush(); err != nil {
+		return fmt.Errorf("svg: %w", err)
+	}
+	return nil
+}
+
+type renderer struct {
+	m       *wxx.Map
+	layout  *hexgrid.Layout
+	palette render.Palette
+	w       *bufio.Writer // errors are sticky and reported by Flush
+}
+
+func (r *renderer) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.w, format, args...)
+}
+
+// tiles fills every tile with the color of its terrain. Tiles are skipped
+// if their terrain layer, "Terrain Land" or "Terrain Water", is hidden.
+func (r *renderer) tiles() {
+	r.printf("<g class=\"tiles\">\n")
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			t := r.m.TileAt(col, row)
+			if t == nil || (t.IsGMOnly && !r.m.ShowGMOnly) {
+				continue
+			} else if !render.IsVisibleLayer(r.m, render.TerrainLayer(r.m, t)) {
+				continue
+			}
+			corners := r.layout.Corners(hexgrid.Hex{Col: col, Row: row})
+			r.printf("<polygon points=\"%s\"%s/>\n", points(corners[:]), fill(render.TileColor(r.m, t, r.palette)))
+		}
+	}
+	r.printf("</g>\n")
+}
+
+// layer draws the shapes, the grid, the features and the labels on the
+// layers that match.
+func (r *renderer) layer(name string, match func(name string) bool) {
+	r.printf("<g class=\"layer\"%s>\n", attr("data-layer", name))
+	for _, s := range r.m.Shapes {
+		if match(s.MapLayer) && r.isVisible(s.IsGMOnly, s.IsWorld, s.IsContinent, s.IsKingdom, s.IsProvince) {
+			r.shape(s)
+		}
+	}
+	if match("Grid") && r.m.ShowGrid {
+		r.grid()
+	}
+	for _, f := range r.m.Features {
+		if match(f.MapLayer) && r.isVisible(f.IsGMOnly, f.IsWorld, f.IsContinent, f.IsKingdom, f.IsProvince) {
+			r.feature(f)
+		}
+	}
+	for _, l := range r.m.Labels {
+		if match(l.MapLayer) && r.isVisible(l.IsGMOnly, l.IsWorld, l.IsContinent, l.IsKingdom, l.IsProvince) {
+			r.label(l)
+		}
+	}
+	r.printf("</g>\n")
+}
+
+// isVisible returns true if an item is shown at the view level of the
+// tiles. GM-only items are only shown if the map shows GM-only content.
+func (r *renderer) isVisible(isGMOnly, world, continent, kingdom, province bool) bool {
+	if isGMOnly && !r.m.ShowGMOnly {
+		return false
+	}
+	return render.AtViewLevel(r.layout.ViewLevel, world, continent, kingdom, province)
+}
+
+// grid outlines every tile and adds the grid numbers if the map shows them.
+func (r *renderer) grid() {
+	g := r.m.GridAndNumbering
+	stroke, err := render.ParseColor(g.Color0)
+	if err != nil {
+		stroke = color.NRGBA{A: 0x40}
+	}
+	width := g.Width0
+	if width <= 0 {
+		width = 1
+	}
+	r.printf("<g class=\"grid\" fill=\"none\"%s stroke-width=\"%s\">\n", paint("stroke", stroke), num(width))
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			corners := r.layout.Corners(hexgrid.Hex{Col: col, Row: row})
+			r.printf("<polygon points=\"%s\"/>\n", points(corners[:]))
+		}
+	}
+	r.printf("</g>\n")
+
+	if !r.m.ShowGridNumbers {
+		return
+	}
+	numbering, err := hexgrid.NewNumbering(r.m)
+	if err != nil {
+		return // Worldographer doesn't draw numbers it can't format either
+	}
+	c, err := render.ParseColor(g.NumberColor)
+	if err != nil {
+		c = color.NRGBA{A: 0xff}
+	}
+	size := render.GridNumberSize(r.m)
+	r.printf("<g class=\"grid-numbers\" text-anchor=\"middle\" dominant-baseline=\"central\"%s%s font-size=\"%s\">\n", fontFamily(g.NumberFont), fill(c), num(size))
+	for col := 0; col < r.m.Width(); col++ {
+		for row := 0; row < r.m.Height(); row++ {
+			h := hexgrid.Hex{Col: col, Row: row}
+			p := render.GridNumberPosition(r.m, r.layout, h)
+			r.printf("<text x=\"%s\" y=\"%s\">%s</text>\n", num(p.X), num(p.Y), text(numbering.Format(h)))
+		}
+	}
+	r.printf("</g>\n")
+}
+
+// shape draws the shape as a path. Polygons are closed and filled if the
+// shape has a fill paint; other shapes are only stroked. Curves are
+// drawn as straight segments.
+func (r *renderer) shape(s *wxx.Shape) {
+	var d strings.Builder
+	for i, p := range s.Points {
+		pt := r.layout.ToTiles(s.CurrentShapeViewLevel, hexgrid.Point{X: p.X, Y: p.Y})
+		command := "L"
+		if i == 0 || p.Type == "m" {
+			command = "M"
+		}
+		if d.Len() != 0 {
+			d.WriteByte(' ')
+		}
+		d.WriteString(fmt.Sprintf("%s%s %s", command, num(pt.X), num(pt.Y)))
+	}
+	if d.Len() == 0 {
+		return
+	}
+	isPolygon := strings.EqualFold(s.Type, "Polygon")
+	if isPolygon {
+		d.WriteString(" Z")
+	}
+
+	var attrs strings.Builder
+	if c, ok := render.ShapeFill(s); isPolygon && ok {
+		attrs.WriteString(fill(c))
+		attrs.WriteString(attr("fill-rule", render.FillRule(s.FillRule)))
+	} else {
+		attrs.WriteString(" fill=\"none\"")
+	}
+	if c, err := render.ParseColor(s.StrokeColor); err == nil && s.StrokeWidth > 0 {
+		attrs.WriteString(paint("stroke", c))
+		attrs.WriteString(attr("stroke-width", num(render.StrokeWidth(r.m, s))))
+		attrs.WriteString(attr("stroke-linecap", strings.ToLower(s.LineCap)))
+		attrs.WriteString(attr("stroke-linejoin", strings.ToLower(s.LineJoin)))
+	}
+	if s.Opacity < 1 {
+		attrs.WriteString(attr("opacity", num(math.Max(0, s.Opacity))))
+	}
+	r.printf("<path d=\"%s\"%s/>\n", d.String(), attrs.String())
+}
+
+// feature marks the feature with a symbol for its type.
+func (r *renderer) feature(f *wxx.Feature) {
+	if f.Location == nil {
+		return
+	}
+	p := r.layout.ToTiles(f.Location.ViewLevel, hexgrid.Point{X: f.Location.X, Y: f.Location.Y})
+	fillColor, ok := render.FromRGBA(f.Color)
+	if !ok {
+		fillColor = render.FeatureColor
+	}
+	ringColor, ok := render.FromRGBA(f.RingColor)
+	if !ok {
+		ringColor = render.FeatureRingColor
+	}
+	size := render.FeatureSize(r.m)
+	style := fill(fillColor) + paint("stroke", ringColor) + attr("stroke-width", num(size/4))
+
+	r.printf("<g class=\"feature\"%s>", attr("data-type", f.Type))
+	r.printf("<title>%s</title>", text(f.Type))
+	switch render.SymbolFor(f.Type) {
+	case render.Square:
+		r.printf("<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"%s/>", num(p.X-size), num(p.Y-size), num(2*size), num(2*size), style)
+	case render.Triangle:
+		r.printf("<polygon points=\"%s\"%s/>", points([]hexgrid.Point{
+			{X: p.X, Y: p.Y - size},
+			{X: p.X + size, Y: p.Y + size},
+			{X: p.X - size, Y: p.Y + size},
+		}), style)
+	default:
+		r.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\"%s/>", num(p.X), num(p.Y), num(size), style)
+	}
+	r.printf("</g>\n")
+}
+
+// label draws the label's text centered on its location.
+func (r *renderer) label(l *wxx.Label) {
+	if l.Location == nil || l.InnerText == "" {
+		return
+	}
+	p := r.layout.ToTiles(l.Location.ViewLevel, hexgrid.Point{X: l.Location.X, Y: l.Location.Y})
+
+	var attrs strings.Builder
+	attrs.WriteString(fontFamily(l.FontFace))
+	attrs.WriteString(attr("font-size", num(render.LabelSize(l))))
+	if l.IsBold {
+		attrs.WriteString(" font-weight=\"bold\"")
+	}
+	if l.IsItalic {
+		attrs.WriteString(" font-style=\"italic\"")
+	}
+	c, ok := render.FromRGBA(l.Color)
+	if !ok {
+		c = color.NRGBA{A: 0xff}
+	}
+	attrs.WriteString(fill(c))
+	if outline, ok := render.FromRGBA(l.OutlineColor); ok && l.OutlineSize > 0 {
+		attrs.WriteString(paint("stroke", outline))
+		attrs.WriteString(attr("stroke-width", num(l.OutlineSize)))
+		attrs.WriteString(" paint-order=\"stroke\"")
+	}
+	if l.Rotate != 0 {
+		attrs.WriteString(attr("transform", fmt.Sprintf("rotate(%s %s %s)", num(l.Rotate), num(p.X), num(p.Y))))
+	}
+
+	lines := strings.Split(l.InnerText, "\n")
+	r.printf("<text class=\"label\" x=\"%s\" y=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\"%s>", num(p.X), num(p.Y), attrs.String())
+	if len(lines) == 1 {
+		r.printf("%s", text(lines[0]))
+	} else {
+		// center the block of lines on the location
+		for i, line := range lines {
+			dy := "1.2em"
+			if i == 0 {
+				dy = num(-0.6*float64(len(lines)-1)) + "em"
+			}
+			r.printf("<tspan x=\"%s\" dy=\"%s\">%s</tspan>", num(p.X), dy, text(line))
+		}
+	}
+	r.printf("</text>\n")
+}
+
+// num formats a number with at most two decimal places.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// points formats the points for a polygon.
+func points(pts []hexgrid.Point) string {
+	var sb strings.Builder
+	for i, p := range pts {
+		if i != 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(num(p.X))
+		sb.WriteByte(',')
+		sb.WriteString(num(p.Y))
+	}
+	return sb.String()
+}
+
+// attr returns the attribute, or nothing if the value is empty.
+func attr(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf(" %s=\"%s\"", name, text(value))
+}
+
+// fill returns the fill attributes for the color.
+func fill(c color.NRGBA) string {
+	return paint("fill", c)
+}
+
+// paint returns the color and opacity attributes for a fill or stroke.
+func paint(name string, c color.NRGBA) string {
+	s := fmt.Sprintf(" %s=\"#%02x%02x%02x\"", name, c.R, c.G, c.B)
+	if c.A != 0xff {
+		s += fmt.Sprintf(" %s-opacity=\"%s\"", name, num(float64(c.A)/255))
+	}
+	return s
+}
+
+// fontFamily returns the font attribute. Worldographer writes "null"
+// for the default font.
+func fontFamily(face string) string {
+	if face == "" || face == "null" {
+		return " font-family=\"sans-serif\""
+	}
+	return attr("font-family", face+", sans-serif")
+}
+
+// text escapes character data and attribute values.
+func text(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
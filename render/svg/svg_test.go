@@ -0,0 +1,207 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("..", "..", "testdata", "maps", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		m := loadMap(t, input)
+		var out bytes.Buffer
+		if err := Render(&out, m, nil); err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+		elements := countElements(t, out.Bytes())
+		if got, want := elements["polygon"], m.Width()*m.Height(); m.ShowGrid && got < want {
+			t.Errorf("%s: got %d polygons, want at least %d", input, got, want)
+		}
+	}
+}
+
+func TestRenderVisibility(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "features-labels.xml"))
+	var out bytes.Buffer
+	if err := Render(&out, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Dungeon") {
+		t.Errorf("GM-only feature was drawn")
+	}
+	if !strings.Contains(out.String(), "Settlement City") {
+		t.Errorf("feature was not drawn")
+	}
+
+	// hiding a layer hides its items
+	for i := range m.MapLayer {
+		if m.MapLayer[i].Name == "Features" {
+			m.MapLayer[i].IsVisible = false
+		}
+	}
+	out.Reset()
+	if err := Render(&out, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Settlement City") {
+		t.Errorf("feature on a hidden layer was drawn")
+	}
+
+	// the map decides whether GM-only content is shown
+	m.ShowGMOnly = true
+	for i := range m.MapLayer {
+		m.MapLayer[i].IsVisible = true
+	}
+	out.Reset()
+	if err := Render(&out, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Dungeon") {
+		t.Errorf("GM-only feature was not drawn")
+	}
+}
+
+func TestRenderTerrainLayers(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "basic.xml"))
+	m.ShowGrid = false
+	m.TileAt(0, 0).Terrain = m.TerrainIndex("Water Sea")
+	water := 0
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			if render.TerrainLayer(m, m.TileAt(col, row)) == "Terrain Water" {
+				water++
+			}
+		}
+	}
+	if water != 1 {
+		t.Fatalf("got %d water tiles, want 1", water)
+	}
+
+	for _, tc := range []struct {
+		hide string
+		want int
+	}{
+		{hide: "", want: m.Width() * m.Height()},
+		{hide: "Terrain Water", want: m.Width()*m.Height() - water},
+		{hide: "Terrain Land", want: water},
+	} {
+		for i := range m.MapLayer {
+			m.MapLayer[i].IsVisible = m.MapLayer[i].Name != tc.hide
+		}
+		var out bytes.Buffer
+		if err := Render(&out, m, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := countElements(t, out.Bytes())["polygon"]; got != tc.want {
+			t.Errorf("hide %q: got %d tiles, want %d", tc.hide, got, tc.want)
+		}
+	}
+}
+
+func TestRenderShapeFill(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "shapes-informations-notes.xml"))
+	s := m.Shapes[0]
+	s.Type = "Polygon"
+	for _, tc := range []struct {
+		name      string
+		fillPaint string
+		want      string
+	}{
+		{name: "no fill paint", want: ` fill="none"`},
+		{name: "null", fillPaint: "null", want: ` fill="none"`},
+		{name: "color", fillPaint: "0.0,0.0,1.0,1.0", want: ` fill="#0000ff"`},
+	} {
+		s.FillTexture = "1.0,0.0,0.0,1.0" // a texture isn't a fill color
+		s.Unknown = nil
+		if tc.fillPaint != "" {
+			s.Unknown = &wxx.Unknown{Attrs: []wxx.Attr{{Name: "fillPaint", Value: tc.fillPaint}}}
+		}
+		var out bytes.Buffer
+		if err := Render(&out, m, nil); err != nil {
+			t.Fatal(err)
+		}
+		var path string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(line, "<path ") {
+				path = line
+				break
+			}
+		}
+		if !strings.Contains(path, tc.want) {
+			t.Errorf("%s: got %s, want %s", tc.name, path, tc.want)
+		}
+	}
+}
+
+func TestRenderEscapes(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "..", "testdata", "maps", "basic.xml"))
+	m.Labels = append(m.Labels, &wxx.Label{
+		MapLayer:  "Labels",
+		FontFace:  `"Odd" <Font>`,
+		IsWorld:   true,
+		Location:  &wxx.LabelLocation{ViewLevel: "WORLD", X: 10, Y: 10},
+		InnerText: "Smith & Sons <est. 1066>\nsecond line",
+	})
+	var out bytes.Buffer
+	if err := Render(&out, m, &Options{Scale: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if countElements(t, out.Bytes())["tspan"] != 2 {
+		t.Errorf("expected a tspan for each line of the label")
+	}
+	if err := Render(io.Discard, m, &Options{Scale: -1}); err == nil {
+		t.Errorf("negative scale: expected error")
+	}
+}
+
+// loadMap imports a UTF-8 test map.
+func loadMap(t *testing.T, path string) *wxx.Map {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wxml, err := adapters.UTF8ToWXML(data)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	m, err := adapters.WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return m
+}
+
+// countElements checks that the document is well-formed and
+// returns the number of each element.
+func countElements(t *testing.T, data []byte) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("svg is not well-formed: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+// texts are the values that have broken the encoder in the past.
+var texts = []string{
+	`"quoted"`,
+	`<b>bold</b>`,
+	`Smith & Sons`,
+	`]]>`,
+	`a]]>b]]]]>c`,
+	"Here be\ndragons",
+	"tab\there, carriage\r\nreturn",
+	"Ünïcödé 日本語 🐉 עברית",
+	`&amp; &#10; &lt;`,
+	"  leading and trailing spaces  ",
+	"\nnew-lines around the text\n\n",
+}
+
+// TestTextRoundTrip checks that text survives import → export → import.
+func TestTextRoundTrip(t *testing.T) {
+	for _, text := range texts {
+		checkTextRoundTrip(t, text)
+	}
+}
+
+// FuzzTextRoundTrip checks that any text that can be stored in an
+// XML document survives import → export → import.
+func FuzzTextRoundTrip(f *testing.F) {
+	for _, text := range texts {
+		f.Add(text)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		if !utf8.ValidString(text) || !isXMLText(text) {
+			t.Skip("text can't be stored in XML")
+		}
+		checkTextRoundTrip(t, text)
+	})
+}
+
+func checkTextRoundTrip(t *testing.T, text string) {
+	t.Helper()
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "shapes-informations-notes.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.MapKey.TitleText = text
+	m.MapLayer[0].Name = text
+	m.Labels = append(m.Labels, &wxx.Label{
+		MapLayer:  text,
+		Tags:      text,
+		InnerText: text,
+		Location:  &wxx.LabelLocation{ViewLevel: "WORLD"},
+	})
+	m.Notes[0].InnerText = text
+	m.Informations.Informations[0].Title = text
+	m.Informations.Informations[0].InnerText = text
+	m.Informations.Informations[0].Details[0].InnerText = text
+	m.Shapes[0].Tags = text
+
+	var out bytes.Buffer
+	if err = Encode(&out, m, nil); err != nil {
+		t.Fatalf("%q: export: %v", text, err)
+	}
+	got, err := Decode(&out, nil)
+	if err != nil {
+		t.Fatalf("%q: import: %v", text, err)
+	}
+	for _, diff := range Diff(m, got) {
+		t.Errorf("%q: %s", text, diff)
+	}
+}
+
+// isXMLText returns true if every rune is allowed in an XML document.
+func isXMLText(s string) bool {
+	for _, r := range s {
+		if !(r == 0x09 || r == 0x0A || r == 0x0D || (r >= 0x20 && r <= 0xD7FF) || (r >= 0xE000 && r <= 0xFFFD) || r >= 0x10000) {
+			return false
+		}
+	}
+	return true
+}
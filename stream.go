@@ -0,0 +1,176 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"time"
+)
+
+// Decode reads a .wxx file from r and converts it to a map.
+//
+// The stages (gzip, UTF-16, XML) are chained as streams, so the input is
+// never buffered in memory.
+func Decode(r io.Reader, opts *Options) (*wxx.Map, error) {
+	ctx := opts.context()
+	started := time.Now()
+
+	utf16Sink, closeUtf16Sink, err := opts.debugArtifact("input-utf-16.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer closeUtf16Sink()
+	utf8Sink, closeUtf8Sink, err := opts.debugArtifact("input-utf-8.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer closeUtf8Sink()
+
+	// unzip input
+	gzr, err := gzip.NewReader(ctxReader{ctx: ctx, r: r})
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	defer func(gzr *gzip.Reader) {
+		_ = gzr.Close() // ignore errors
+	}(gzr)
+
+	gzStage := &timedReader{r: gzr}
+	var src io.Reader = gzStage
+	if utf16Sink != nil {
+		src = io.TeeReader(src, utf16Sink)
+	}
+
+	// convert input from UTF-16 to UTF-8
+	utf16Stage := &timedReader{r: adapters.NewUTF16ToUTF8Reader(src)}
+	src = utf16Stage
+	if utf8Sink != nil {
+		src = io.TeeReader(src, utf8Sink)
+	}
+
+	// convert UTF-8 to WXML
+	step := time.Now()
+	wxml, err := adapters.ReadWXML(src)
+	if err != nil {
+		return nil, err
+	}
+	// consume the rest of the input so that the gzip checksum is verified
+	if _, err = io.Copy(io.Discard, src); err != nil {
+		return nil, err
+	}
+	xmlElapsed := time.Since(step)
+	opts.timing("gzip", gzStage.elapsed)
+	opts.timing("utf-16", utf16Stage.elapsed-gzStage.elapsed)
+	opts.timing("xml", xmlElapsed-utf16Stage.elapsed)
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// convert the WXML to WMAP
+	step = time.Now()
+	wmap, err := adapters.WXMLToWXX(wxml, opts.logger())
+	if err != nil {
+		return nil, err
+	}
+	opts.timing("translate", time.Since(step))
+
+	step = time.Now()
+	if err = opts.transform(wmap); err != nil {
+		return nil, err
+	}
+	opts.timing("transform", time.Since(step))
+
+	opts.timing("decode", time.Since(started))
+
+	return wmap, nil
+}
+
+// Encode converts the map to a .wxx file and writes it to w.
+//
+// The stages (XML, UTF-16, gzip) are chained as streams, so the output is
+// never buffered in memory.
+func Encode(w io.Writer, m *wxx.Map, opts *Options) error {
+	ctx := opts.context()
+	started := time.Now()
+
+	m, err := opts.export(m)
+	if err != nil {
+		return err
+	}
+
+	version, err := targetVersion(m, opts.targetVersion())
+	if err != nil {
+		return err
+	}
+
+	utf8Sink, closeUtf8Sink, err := opts.debugArtifact("output-utf-8.xml")
+	if err != nil {
+		return err
+	}
+	defer closeUtf8Sink()
+	utf16Sink, closeUtf16Sink, err := opts.debugArtifact("output-utf-16.xml")
+	if err != nil {
+		return err
+	}
+	defer closeUtf16Sink()
+
+	gzw := gzip.NewWriter(ctxWriter{ctx: ctx, w: w})
+	gzStage := &timedWriter{w: gzw}
+
+	var dst io.Writer = gzStage
+	if utf16Sink != nil {
+		dst = io.MultiWriter(dst, utf16Sink)
+	}
+
+	// convert UTF-8 to UTF-16
+	utf16w := adapters.NewUTF8ToUTF16Writer(dst)
+	utf16Stage := &timedWriter{w: utf16w}
+	dst = utf16Stage
+	if utf8Sink != nil {
+		dst = io.MultiWriter(dst, utf8Sink)
+	}
+
+	// convert the WMAP to UTF-8 XML
+	step := time.Now()
+	bw := bufio.NewWriter(dst)
+	if err = adapters.WXXToUTF8(bw, m, version); err != nil {
+		return fmt.Errorf("encode %s: %w", version, err)
+	} else if err = bw.Flush(); err != nil {
+		return err
+	} else if err = utf16w.Close(); err != nil {
+		return err
+	}
+	xmlElapsed := time.Since(step)
+
+	step = time.Now()
+	if err = gzw.Close(); err != nil {
+		return err
+	}
+	opts.timing("xml", xmlElapsed-utf16Stage.elapsed)
+	opts.timing("utf-16", utf16Stage.elapsed-gzStage.elapsed)
+	opts.timing("gzip", gzStage.elapsed+time.Since(step))
+
+	opts.timing("encode", time.Since(started))
+
+	return nil
+}
+
+// targetVersion returns the version of the .wxx file to create.
+func targetVersion(m *wxx.Map, version string) (string, error) {
+	if version != "" {
+		if _, err := adapters.LookupEncoder(version); err != nil {
+			return "", err
+		}
+		return version, nil
+	}
+	if _, err := adapters.LookupEncoder(m.Version); err == nil {
+		return m.Version, nil
+	}
+	return adapters.DefaultVersion, nil
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/models/wxx"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// tilesCSVHeader are the columns written by ExportTilesCSV.
+var tilesCSVHeader = []string{
+	"col", "row", "terrain", "elevation", "icy", "gm_only",
+	"animal", "brick", "crops", "gems", "lumber", "metals", "rock",
+	"background_color",
+}
+
+// ExportTilesCSV writes one row for each tile of the map, column by
+// column. The terrain is written as the label from the terrain map and
+// the background color as "r,g,b,a", or empty if the tile doesn't have one.
+func ExportTilesCSV(w io.Writer, m *wxx.Map) error {
+	labels := map[int]string{}
+	for _, t := range m.TerrainMap.List {
+		labels[t.Index] = t.Label
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(tilesCSVHeader); err != nil {
+		return err
+	}
+	for col := 0; col < m.Width(); col++ {
+		for row := 0; row < m.Height(); row++ {
+			t := m.TileAt(col, row)
+			if t == nil {
+				return fmt.Errorf("tiles csv: %d,%d: missing tile", col, row)
+			}
+			terrain, ok := labels[t.Terrain]
+			if !ok {
+				return fmt.Errorf("tiles csv: %d,%d: terrain %d: not in terrain map", col, row, t.Terrain)
+			}
+			var color string
+			if c := t.CustomBackgroundColor; c != nil {
+				color = fmt.Sprintf("%s,%s,%s,%s", adapters.FToXF(c.R), adapters.FToXF(c.G), adapters.FToXF(c.B), adapters.FToXF(c.A))
+			}
+			record := []string{
+				strconv.Itoa(col),
+				strconv.Itoa(row),
+				terrain,
+				strconv.FormatFloat(t.Elevation, 'f', -1, 64),
+				strconv.FormatBool(t.IsIcy),
+				strconv.FormatBool(t.IsGMOnly),
+				strconv.Itoa(t.Resources.Animal),
+				strconv.Itoa(t.Resources.Brick),
+				strconv.Itoa(t.Resources.Crops),
+				strconv.Itoa(t.Resources.Gems),
+				strconv.Itoa(t.Resources.Lumber),
+				strconv.Itoa(t.Resources.Metals),
+				strconv.Itoa(t.Resources.Rock),
+				color,
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportTilesCSV applies a CSV file in the format written by
+// ExportTilesCSV to the tiles of the map.
+//
+// The "col" and "row" columns are required; the other columns are
+// optional and only the values in the file are changed. A column may
+// only appear once. Terrain that
+// isn't in the terrain map is added to it. If any row is invalid, an
+// error is returned and the map is not changed.
+func ImportTilesCSV(r io.Reader, m *wxx.Map) error {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("tiles csv: header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range tilesCSVHeader {
+			known = known || name == column
+		}
+		if !known {
+			return fmt.Errorf("tiles csv: header: %q: unknown column", name)
+		} else if _, ok := columns[name]; ok {
+			return fmt.Errorf("tiles csv: header: %q: duplicate column", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["col"]; !ok {
+		return fmt.Errorf("tiles csv: header: missing col column")
+	} else if _, ok = columns["row"]; !ok {
+		return fmt.Errorf("tiles csv: header: missing row column")
+	}
+
+	// parse every row before changing the map
+	type update struct {
+		col, row int
+		fields   map[string]string
+	}
+	var updates []update
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("tiles csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		u := update{fields: map[string]string{}}
+		for name, i := range columns {
+			u.fields[name] = record[i]
+		}
+		if u.col, err = strconv.Atoi(u.fields["col"]); err != nil {
+			return fmt.Errorf("tiles csv: line %d: col: %w", line, err)
+		} else if u.row, err = strconv.Atoi(u.fields["row"]); err != nil {
+			return fmt.Errorf("tiles csv: line %d: row: %w", line, err)
+		} else if u.col < 0 || u.col >= m.Width() || u.row < 0 || u.row >= m.Height() {
+			return fmt.Errorf("tiles csv: line %d: %d,%d: outside of %dx%d map", line, u.col, u.row, m.Width(), m.Height())
+		} else if _, err = applyTileFields(&wxx.Tile{}, u.fields, nil); err != nil {
+			return fmt.Errorf("tiles csv: line %d: %w", line, err)
+		}
+		updates = append(updates, u)
+	}
+
+	for _, u := range updates {
+		t := m.TileAt(u.col, u.row)
+		if t == nil {
+			t = &wxx.Tile{}
+		}
+		if t, err = applyTileFields(t, u.fields, m); err != nil {
+			return fmt.Errorf("tiles csv: %d,%d: %w", u.col, u.row, err)
+		} else if err = m.SetTile(u.col, u.row, t); err != nil {
+			return fmt.Errorf("tiles csv: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyTileFields sets the tile's values from the fields of a row.
+// If m is nil, the terrain is not looked up, so the fields can be
+// checked without changing the map.
+func applyTileFields(t *wxx.Tile, fields map[string]string, m *wxx.Map) (*wxx.Tile, error) {
+	var err error
+	for name, value := range fields {
+		switch name {
+		case "terrain":
+			if value == "" {
+				return nil, fmt.Errorf("terrain: missing value")
+			} else if m != nil {
+				t.Terrain = m.TerrainIndex(value)
+			}
+		case "elevation":
+			if t.Elevation, err = strconv.ParseFloat(value, 64); err == nil && (math.IsNaN(t.Elevation) || math.IsInf(t.Elevation, 0)) {
+				err = fmt.Errorf("%q: invalid value", value)
+			}
+		case "icy":
+			t.IsIcy, err = strconv.ParseBool(value)
+		case "gm_only":
+			t.IsGMOnly, err = strconv.ParseBool(value)
+		case "animal":
+			t.Resources.Animal, err = parseResource(value)
+		case "brick":
+			t.Resources.Brick, err = parseResource(value)
+		case "crops":
+			t.Resources.Crops, err = parseResource(value)
+		case "gems":
+			t.Resources.Gems, err = parseResource(value)
+		case "lumber":
+			t.Resources.Lumber, err = parseResource(value)
+		case "metals":
+			t.Resources.Metals, err = parseResource(value)
+		case "rock":
+			t.Resources.Rock, err = parseResource(value)
+		case "background_color":
+			t.CustomBackgroundColor, err = parseTileColor(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// parseResource parses a resource value, which must be 0 to 100.
+func parseResource(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	} else if v < 0 || v > 100 {
+		return 0, fmt.Errorf("invalid value %d", v)
+	}
+	return v, nil
+}
+
+// parseTileColor parses "r,g,b,a". An empty value is no color.
+func parseTileColor(s string) (*wxx.RGBA, error) {
+	if s == "" {
+		return nil, nil
+	}
+	values := strings.Split(s, ",")
+	if len(values) != 4 {
+		return nil, fmt.Errorf("%q: expected r,g,b,a", s)
+	}
+	var rgba [4]float64
+	for i, value := range values {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, err
+		} else if f < 0 || f > 1 {
+			return nil, fmt.Errorf("%q: values must be between 0 and 1", s)
+		}
+		rgba[i] = f
+	}
+	return &wxx.RGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]}, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTilesCSV(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "tile-colors.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeWXX(t, src)
+	m, err := Decode(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var csvData bytes.Buffer
+	if err = ExportTilesCSV(&csvData, m); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(csvData.String(), "\n"); lines != 1+m.Width()*m.Height() {
+		t.Errorf("export: got %d lines, want %d", lines, 1+m.Width()*m.Height())
+	}
+
+	// applying the export to a blank copy of the map restores the tiles
+	c, err := Decode(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Tiles.TileRows = nil
+	if err = ImportTilesCSV(bytes.NewReader(csvData.Bytes()), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range Diff(m, c) {
+		t.Errorf("round trip: %s", diff)
+	}
+
+	// a GM-only tile's values don't reach a player's copy
+	secret := m.TileAt(2, 1)
+	secret.IsGMOnly, secret.Terrain = true, m.TerrainIndex("Secret Caverns")
+	secret.Resources.Animal, secret.Resources.Gems = 42, 77
+	stripped, err := StripGMOnly(m, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvData.Reset()
+	if err = ExportTilesCSV(&csvData, stripped); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\n2,1,Blank,0,false,false,0,0,0,0,0,0,0,\n"; !strings.Contains(csvData.String(), want) {
+		t.Errorf("strip-gm: got %q, want a row %q", csvData.String(), want)
+	}
+	for _, secret := range []string{"Secret Caverns", "42", "77"} {
+		if strings.Contains(csvData.String(), secret) {
+			t.Errorf("strip-gm: found %q in %q", secret, csvData.String())
+		}
+	}
+
+	// only the columns in the file are changed
+	terrain, elevation := m.TileAt(0, 0).Terrain, m.TileAt(0, 0).Elevation
+	if err = ImportTilesCSV(strings.NewReader("col,row,gm_only,gems\n0,0,true,5\n"), m); err != nil {
+		t.Fatal(err)
+	}
+	if tile := m.TileAt(0, 0); !tile.IsGMOnly || tile.Resources.Gems != 5 || tile.Terrain != terrain || tile.Elevation != elevation {
+		t.Errorf("partial import: got %+v", *tile)
+	}
+
+	// new terrain is added to the terrain map
+	if err = ImportTilesCSV(strings.NewReader("col,row,terrain\n0,0,Lava Field\n"), m); err != nil {
+		t.Fatal(err)
+	}
+	if index, ok := m.TerrainMap.Data["Lava Field"]; !ok || m.TileAt(0, 0).Terrain != index {
+		t.Errorf("terrain: new terrain not added")
+	}
+
+	// resources are checked like the other imports check them
+	if err := ImportTilesCSV(strings.NewReader("col,row,animal\n0,1,500\n"), m); err == nil || !strings.Contains(err.Error(), "animal: invalid value 500") {
+		t.Errorf("resource: got %v, want invalid value", err)
+	}
+	if err := ImportTilesCSV(strings.NewReader("col,row,gems,gems\n0,1,1,2\n"), m); err == nil || !strings.Contains(err.Error(), "duplicate column") {
+		t.Errorf("header: got %v, want duplicate column", err)
+	}
+
+	// errors don't change the map
+	for _, input := range []string{
+		"row,terrain\n0,Blank\n",
+		"col,row,color\n0,0,red\n",
+		"col,row,elevation\n0,1,100\n0,0,high\n",
+		"col,row,elevation\n0,1,NaN\n",
+		"col,row,icy\n0,1,maybe\n",
+		"col,row,background_color\n0,1,1.0,0.5\n",
+		"col,row,background_color\n0,1,\"1.0,0.5,0.5\"\n",
+		"col,row,background_color\n0,1,\"2.0,0.5,0.5,1.0\"\n",
+		"col,row\n99,0\n",
+		"col,row,terrain\n0,1,\n",
+		"col,row,animal\n0,1,500\n",
+		"col,row,rock\n0,1,-3\n",
+		"col,row,gems\n0,1,101\n",
+		"col,row,elevation,elevation\n0,1,100,200\n",
+		"col,row,Col\n0,1,1\n",
+	} {
+		before := m.TileAt(0, 1).Elevation
+		if err := ImportTilesCSV(strings.NewReader(input), m); err == nil {
+			t.Errorf("%q: expected error", input)
+		} else if m.TileAt(0, 1).Elevation != before {
+			t.Errorf("%q: map was changed", input)
+		}
+	}
+}
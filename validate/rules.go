@@ -0,0 +1,348 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package validate
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/mdhender/wxconv/hexgrid"
+	"github.com/mdhender/wxconv/models/wxx"
+	"github.com/mdhender/wxconv/render"
+)
+
+// blankTerrain is the terrain given to tiles that are added to the grid.
+const blankTerrain = "Blank"
+
+// checkTileGrid reports a grid that doesn't have TilesWide columns
+// of TilesHigh rows, or that is missing tiles.
+func checkTileGrid(m *wxx.Map) []*Finding {
+	var findings []*Finding
+	if m.Width() < 0 || m.Height() < 0 {
+		return []*Finding{{
+			Severity: Error,
+			Path:     "Tiles",
+			Message:  fmt.Sprintf("invalid size %dx%d", m.Width(), m.Height()),
+			Fix:      "set TilesWide and TilesHigh to the size of the grid",
+		}}
+	}
+	resize := func(m *wxx.Map) error {
+		columns := m.Tiles.TileRows
+		if len(columns) > m.Width() {
+			columns = columns[:m.Width()]
+		}
+		for col := range columns {
+			if len(columns[col]) > m.Height() {
+				columns[col] = columns[col][:m.Height()]
+			}
+		}
+		m.Tiles.TileRows = columns
+		terrain := -1 // only added to the terrain map if a tile needs it
+		for col := 0; col < m.Width(); col++ {
+			for row := 0; row < m.Height(); row++ {
+				if m.TileAt(col, row) != nil {
+					continue
+				}
+				if terrain == -1 {
+					terrain = m.TerrainIndex(blankTerrain)
+				}
+				if err := m.SetTile(col, row, &wxx.Tile{Terrain: terrain}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	fix := fmt.Sprintf("resize the grid to %dx%d, adding %s tiles", m.Width(), m.Height(), blankTerrain)
+
+	if len(m.Tiles.TileRows) != m.Width() {
+		findings = append(findings, &Finding{
+			Severity: Error,
+			Path:     "Tiles.TileRows",
+			Message:  fmt.Sprintf("got %d columns, want %d", len(m.Tiles.TileRows), m.Width()),
+			Fix:      fix,
+			fix:      resize,
+		})
+	}
+	for col, column := range m.Tiles.TileRows {
+		if col >= m.Width() {
+			break
+		}
+		if len(column) != m.Height() {
+			findings = append(findings, &Finding{
+				Severity: Error,
+				Path:     fmt.Sprintf("Tiles.TileRows[%d]", col),
+				Message:  fmt.Sprintf("got %d rows, want %d", len(column), m.Height()),
+				Fix:      fix,
+				fix:      resize,
+			})
+		}
+		for row, t := range column {
+			if t == nil && row < m.Height() {
+				findings = append(findings, &Finding{
+					Severity: Error,
+					Path:     fmt.Sprintf("Tiles.TileRows[%d][%d]", col, row),
+					Message:  "missing tile",
+					Fix:      fix,
+					fix:      resize,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// checkTerrain reports tiles whose terrain isn't in the terrain map.
+func checkTerrain(m *wxx.Map) []*Finding {
+	known := map[int]bool{}
+	for _, t := range m.TerrainMap.List {
+		known[t.Index] = true
+	}
+	var findings []*Finding
+	for col, column := range m.Tiles.TileRows {
+		for row, t := range column {
+			if t == nil || known[t.Terrain] {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Severity: Error,
+				Path:     fmt.Sprintf("Tiles.TileRows[%d][%d].Terrain", col, row),
+				Message:  fmt.Sprintf("terrain %d is not in the terrain map", t.Terrain),
+				Fix:      "add the terrain to the terrain map or change the tile's terrain",
+			})
+		}
+	}
+	return findings
+}
+
+// checkMapLayers reports features, labels and shapes that are on
+// a layer that the map doesn't have.
+func checkMapLayers(m *wxx.Map) []*Finding {
+	layers := map[string]bool{}
+	for _, l := range m.MapLayer {
+		layers[l.Name] = true
+	}
+	var findings []*Finding
+	check := func(path, layer string) {
+		if layers[layer] {
+			return
+		}
+		findings = append(findings, &Finding{
+			Severity: Error,
+			Path:     path,
+			Message:  fmt.Sprintf("layer %q is not in the map's layers", layer),
+			Fix:      fmt.Sprintf("add a visible %q layer to the top of the map's layers", layer),
+			fix: func(m *wxx.Map) error {
+				for _, l := range m.MapLayer {
+					if l.Name == layer {
+						return nil // already added by another fix
+					}
+				}
+				m.MapLayer = append([]wxx.MapLayer{{Name: layer, IsVisible: true}}, m.MapLayer...)
+				return nil
+			},
+		})
+	}
+	for i, f := range m.Features {
+		check(fmt.Sprintf("Features[%d].MapLayer", i), f.MapLayer)
+		if f.Label != nil {
+			check(fmt.Sprintf("Features[%d].Label.MapLayer", i), f.Label.MapLayer)
+		}
+	}
+	for i, l := range m.Labels {
+		check(fmt.Sprintf("Labels[%d].MapLayer", i), l.MapLayer)
+	}
+	for i, s := range m.Shapes {
+		check(fmt.Sprintf("Shapes[%d].MapLayer", i), s.MapLayer)
+	}
+	return findings
+}
+
+// checkLabelStyles reports labels whose style isn't in the text
+// configuration. A style of "null" means the label has no style.
+func checkLabelStyles(m *wxx.Map) []*Finding {
+	styles := map[string]bool{"": true, "null": true}
+	for _, s := range m.Configuration.TextConfig.LabelStyles {
+		styles[s.Name] = true
+	}
+	var findings []*Finding
+	check := func(path string, l *wxx.Label) {
+		if styles[l.Style] {
+			return
+		}
+		findings = append(findings, &Finding{
+			Severity: Warning,
+			Path:     path,
+			Message:  fmt.Sprintf("style %q is not in the label styles", l.Style),
+			Fix:      "add the style to the label styles or remove it from the label",
+			fix: func(m *wxx.Map) error {
+				l.Style = "null"
+				return nil
+			},
+		})
+	}
+	for i, f := range m.Features {
+		if f.Label != nil {
+			check(fmt.Sprintf("Features[%d].Label.Style", i), f.Label)
+		}
+	}
+	for i, l := range m.Labels {
+		check(fmt.Sprintf("Labels[%d].Style", i), l)
+	}
+	return findings
+}
+
+// checkUUIDs reports features that share a UUID, and information entries
+// that share a UUID. An information entry has the UUID of the feature
+// that it describes, so the two lists are checked separately.
+func checkUUIDs(m *wxx.Map) []*Finding {
+	var findings []*Finding
+
+	features := map[string]int{}
+	for i, f := range m.Features {
+		if f.Uuid == "" {
+			continue
+		}
+		first, ok := features[f.Uuid]
+		if !ok {
+			features[f.Uuid] = i
+			continue
+		}
+		f := f
+		findings = append(findings, &Finding{
+			Severity: Error,
+			Path:     fmt.Sprintf("Features[%d].Uuid", i),
+			Message:  fmt.Sprintf("uuid %q is also used by Features[%d]", f.Uuid, first),
+			Fix:      "give the feature a new UUID; its information entries stay with the first feature",
+			fix: func(m *wxx.Map) error {
+				uuid, err := newUUID()
+				if err != nil {
+					return err
+				}
+				f.Uuid = uuid
+				return nil
+			},
+		})
+	}
+
+	informations := map[string]string{}
+	check := func(path, uuid string) {
+		if uuid == "" {
+			return
+		} else if first, ok := informations[uuid]; ok {
+			findings = append(findings, &Finding{
+				Severity: Error,
+				Path:     path,
+				Message:  fmt.Sprintf("uuid %q is also used by %s", uuid, first),
+				Fix:      "merge the information entries or remove one of them",
+			})
+			return
+		}
+		informations[uuid] = path
+	}
+	for i, info := range m.Informations.Informations {
+		check(fmt.Sprintf("Informations.Informations[%d].Uuid", i), info.Uuid)
+		for j, detail := range info.Details {
+			check(fmt.Sprintf("Informations.Informations[%d].Details[%d].Uuid", i, j), detail.Uuid)
+		}
+	}
+
+	return findings
+}
+
+// checkResources reports tile resources that are outside of 0 to 100.
+func checkResources(m *wxx.Map) []*Finding {
+	var findings []*Finding
+	for col, column := range m.Tiles.TileRows {
+		for row, t := range column {
+			if t == nil {
+				continue
+			}
+			for _, r := range []struct {
+				name  string
+				value *int
+			}{
+				{"Animal", &t.Resources.Animal},
+				{"Brick", &t.Resources.Brick},
+				{"Crops", &t.Resources.Crops},
+				{"Gems", &t.Resources.Gems},
+				{"Lumber", &t.Resources.Lumber},
+				{"Metals", &t.Resources.Metals},
+				{"Rock", &t.Resources.Rock},
+			} {
+				if 0 <= *r.value && *r.value <= 100 {
+					continue
+				}
+				value := r.value
+				findings = append(findings, &Finding{
+					Severity: Error,
+					Path:     fmt.Sprintf("Tiles.TileRows[%d][%d].Resources.%s", col, row, r.name),
+					Message:  fmt.Sprintf("got %d, want 0 to 100", *r.value),
+					Fix:      fmt.Sprintf("set it to %d", min(max(*r.value, 0), 100)),
+					fix: func(m *wxx.Map) error {
+						*value = min(max(*value, 0), 100)
+						return nil
+					},
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// checkBounds reports features, labels and shape points that are
+// outside the rectangle that holds the tiles.
+func checkBounds(m *wxx.Map) []*Finding {
+	layout, err := hexgrid.NewLayout(m)
+	if err != nil {
+		return []*Finding{{
+			Severity: Error,
+			Path:     "HexOrientation",
+			Message:  err.Error(),
+			Fix:      fmt.Sprintf("set it to %s or %s", wxx.OrientationColumns, wxx.OrientationRows),
+		}}
+	}
+	width, height := render.Size(m, layout)
+	if width == 0 || height == 0 {
+		return nil // the tile-grid rule reports empty maps
+	}
+	var findings []*Finding
+	check := func(path, viewLevel string, x, y float64) {
+		p := layout.ToTiles(viewLevel, hexgrid.Point{X: x, Y: y})
+		if 0 <= p.X && p.X <= width && 0 <= p.Y && p.Y <= height {
+			return
+		}
+		findings = append(findings, &Finding{
+			Severity: Warning,
+			Path:     path,
+			Message:  fmt.Sprintf("%g,%g is outside the %gx%g map", x, y, width, height),
+			Fix:      "move it onto the map or delete it",
+		})
+	}
+	for i, f := range m.Features {
+		if f.Location != nil {
+			check(fmt.Sprintf("Features[%d].Location", i), f.Location.ViewLevel, f.Location.X, f.Location.Y)
+		}
+	}
+	for i, l := range m.Labels {
+		if l.Location != nil {
+			check(fmt.Sprintf("Labels[%d].Location", i), l.Location.ViewLevel, l.Location.X, l.Location.Y)
+		}
+	}
+	for i, s := range m.Shapes {
+		for j, p := range s.Points {
+			check(fmt.Sprintf("Shapes[%d].Points[%d]", i, j), s.CurrentShapeViewLevel, p.X, p.Y)
+		}
+	}
+	return findings
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+// Package validate checks a map for problems that Worldographer would
+// reject or mishandle. Each problem is reported as a Finding; some
+// findings can be fixed automatically.
+package validate
+
+import (
+	"fmt"
+	"github.com/mdhender/wxconv/models/wxx"
+)
+
+// Severity is how serious a finding is.
+type Severity int
+
+const (
+	// Warning is a problem that Worldographer tolerates,
+	// but that probably isn't what the author wanted.
+	Warning Severity = iota
+	// Error is a problem that breaks the map in Worldographer.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Finding is a problem found by a rule.
+type Finding struct {
+	Rule     string   // name of the rule that found the problem
+	Severity Severity // how serious the problem is
+	Path     string   // path to the value, e.g. "Features[2].MapLayer"
+	Message  string   // what is wrong
+	Fix      string   // how to fix it
+
+	// fix changes the map to fix the problem. It is nil if the
+	// problem can't be fixed automatically.
+	fix func(m *wxx.Map) error
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Path, f.Message, f.Rule)
+}
+
+// CanFix returns true if the finding can be fixed automatically.
+func (f *Finding) CanFix() bool {
+	return f.fix != nil
+}
+
+// Rule is a check of the map.
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(m *wxx.Map) []*Finding
+}
+
+// Rules are the checks that Validate runs, in order.
+var Rules = []*Rule{
+	{Name: "tile-grid", Description: "the tile grid matches TilesWide and TilesHigh", Check: checkTileGrid},
+	{Name: "terrain", Description: "every tile's terrain is in the terrain map", Check: checkTerrain},
+	{Name: "map-layer", Description: "every feature, label and shape is on a layer of the map", Check: checkMapLayers},
+	{Name: "label-style", Description: "every label's style is in the text configuration", Check: checkLabelStyles},
+	{Name: "uuid", Description: "features and information entries have unique UUIDs", Check: checkUUIDs},
+	{Name: "resources", Description: "tile resources are between 0 and 100", Check: checkResources},
+	{Name: "bounds", Description: "features, labels and shapes are inside the map", Check: checkBounds},
+}
+
+// Validate runs all the rules against the map and returns the findings.
+func Validate(m *wxx.Map) []*Finding {
+	var findings []*Finding
+	for _, rule := range Rules {
+		for _, f := range rule.Check(m) {
+			f.Rule = rule.Name
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Fix applies the automatic fixes for the findings to the map and
+// returns the number of findings that were fixed. The findings must
+// come from validating the same map, and the map should be validated
+// again after fixing since one fix may change what the others found.
+func Fix(m *wxx.Map, findings []*Finding) (int, error) {
+	fixed := 0
+	for _, f := range findings {
+		if !f.CanFix() {
+			continue
+		}
+		if err := f.fix(m); err != nil {
+			return fixed, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		fixed++
+	}
+	return fixed, nil
+}
+
+// HasErrors returns true if any of the findings are errors.
+func HasErrors(findings []*Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package validate
+
+import (
+	"github.com/mdhender/wxconv/adapters"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("..", "testdata", "maps", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		if findings := Validate(loadMap(t, input)); HasErrors(findings) {
+			t.Errorf("%s: got %v, want no errors", input, findings)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	m := loadMap(t, filepath.Join("..", "testdata", "maps", "features-labels.xml"))
+	m.Tiles.TileRows[1] = m.Tiles.TileRows[1][:1]
+	m.TileAt(0, 1).Terrain = 99
+	m.TileAt(0, 0).Resources.Gems = 150
+	m.Features[0].MapLayer = "Secret"
+	m.Features[1].Uuid = m.Features[0].Uuid
+	m.Labels[0].Style = "Ocean"
+	m.Labels[1].Location.X = -500
+
+	want := map[string]struct {
+		rule   string
+		canFix bool
+	}{
+		"Tiles.TileRows[1]":                   {"tile-grid", true},
+		"Tiles.TileRows[0][1].Terrain":        {"terrain", false},
+		"Tiles.TileRows[0][0].Resources.Gems": {"resources", true},
+		"Features[0].MapLayer":                {"map-layer", true},
+		"Features[1].Uuid":                    {"uuid", true},
+		"Labels[0].Style":                     {"label-style", true},
+		"Labels[1].Location":                  {"bounds", false},
+	}
+	findings := Validate(m)
+	for _, f := range findings {
+		w, ok := want[f.Path]
+		if !ok {
+			t.Errorf("unexpected finding %s", f)
+			continue
+		}
+		if f.Rule != w.rule {
+			t.Errorf("%s: got rule %q, want %q", f.Path, f.Rule, w.rule)
+		}
+		if f.CanFix() != w.canFix {
+			t.Errorf("%s: got can fix %v, want %v", f.Path, f.CanFix(), w.canFix)
+		}
+		if f.Fix == "" {
+			t.Errorf("%s: missing fix suggestion", f.Path)
+		}
+		delete(want, f.Path)
+	}
+	for path, w := range want {
+		t.Errorf("%s: missing %s finding", path, w.rule)
+	}
+
+	fixed, err := Fix(m, findings)
+	if err != nil {
+		t.Fatal(err)
+	} else if fixed != 5 {
+		t.Errorf("fix: got %d fixed, want 5", fixed)
+	}
+	remaining := map[string]bool{}
+	for _, f := range Validate(m) {
+		remaining[f.Path] = true
+	}
+	if len(remaining) != 2 || !remaining["Tiles.TileRows[0][1].Terrain"] || !remaining["Labels[1].Location"] {
+		t.Errorf("fix: got %v remaining, want the terrain and bounds findings", remaining)
+	}
+	if m.Features[0].Uuid == m.Features[1].Uuid {
+		t.Errorf("fix: features still share uuid %q", m.Features[0].Uuid)
+	}
+	if got := m.TileAt(0, 0).Resources.Gems; got != 100 {
+		t.Errorf("fix: got %d gems, want 100", got)
+	}
+}
+
+func loadMap(t *testing.T, path string) *wxx.Map {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wxml, err := adapters.UTF8ToWXML(data)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	m, err := adapters.WXMLToWXX(wxml, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return m
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// VerifyReport is the result of a round-trip check.
+type VerifyReport struct {
+	// Version is the version of the .wxx file that was created.
+	Version string
+	// Differences between the imported map and the re-imported map.
+	Differences []Difference
+	// XMLDifferences between the canonical forms of the input and
+	// output documents. Only set if requested.
+	XMLDifferences []Difference
+}
+
+// OK returns true if no differences were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Differences) == 0 && len(r.XMLDifferences) == 0
+}
+
+// Verify proves that a conversion is faithful. It imports the .wxx file
+// from r, exports it in memory, re-imports the result, and reports the
+// differences between the two maps. If compareXML is true, it also
+// reports the differences between the canonical forms of the input and
+// output XML documents.
+func Verify(r io.Reader, compareXML bool, opts *Options) (*VerifyReport, error) {
+	// capture the UTF-8 documents as they stream past
+	var input, output bytes.Buffer
+	var vopts Options
+	if opts != nil {
+		vopts = *opts
+	}
+	vopts.DebugSink = func(name string) (io.WriteCloser, error) {
+		var capture io.Writer
+		switch name {
+		case "input-utf-8.xml":
+			capture = &input
+		case "output-utf-8.xml":
+			capture = &output
+		}
+		if opts == nil || opts.DebugSink == nil {
+			if capture == nil {
+				return nopWriteCloser{Writer: io.Discard}, nil
+			}
+			return nopWriteCloser{Writer: capture}, nil
+		}
+		w, err := opts.DebugSink(name)
+		if err != nil || capture == nil {
+			return w, err
+		}
+		return teeWriteCloser{Writer: io.MultiWriter(w, capture), c: w}, nil
+	}
+
+	a, err := Decode(r, &vopts)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	version, err := targetVersion(a, vopts.targetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	var wxxData bytes.Buffer
+	if err = Encode(&wxxData, a, &vopts); err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+
+	// don't overwrite the artifacts from the first import
+	vopts.DebugSink = nil
+	b, err := Decode(&wxxData, &vopts)
+	if err != nil {
+		return nil, fmt.Errorf("re-import: %w", err)
+	}
+
+	report := &VerifyReport{
+		Version:     version,
+		Differences: Diff(a, b),
+	}
+	if compareXML {
+		report.XMLDifferences, err = DiffXML(input.Bytes(), output.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// DiffXML returns the differences between the canonical forms of two
+// XML documents. The canonical form ignores the order of attributes,
+// whitespace between elements, leading and trailing whitespace in text,
+// comments, and processing instructions.
+//
+// Differences are reported until the structure of the documents
+// diverges; after that, the positions no longer line up.
+func DiffXML(a, b []byte) ([]Difference, error) {
+	ca, err := canonicalXML(a)
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	cb, err := canonicalXML(b)
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	var diffs []Difference
+	for i := 0; i < len(ca) && i < len(cb); i++ {
+		if ca[i].path != cb[i].path {
+			diffs = append(diffs, Difference{Path: ca[i].path, A: ca[i].path, B: cb[i].path})
+			return diffs, nil
+		} else if ca[i].value != cb[i].value {
+			diffs = append(diffs, Difference{Path: ca[i].path, A: ca[i].value, B: cb[i].value})
+		}
+	}
+	if len(ca) != len(cb) {
+		diffs = append(diffs, Difference{Path: "nodes", A: fmt.Sprint(len(ca)), B: fmt.Sprint(len(cb))})
+	}
+	return diffs, nil
+}
+
+// canonicalNode is an attribute or text of an element.
+type canonicalNode struct {
+	path  string // e.g. "map/features/feature[2]/@uuid"
+	value string
+}
+
+// canonicalXML flattens the document into a list of nodes.
+func canonicalXML(data []byte) ([]canonicalNode, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil // the document has already been converted to UTF-8
+	}
+
+	type frame struct {
+		path     string
+		children map[string]int
+		text     strings.Builder
+	}
+	var nodes []canonicalNode
+	stack := []*frame{{children: map[string]int{}}}
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		top := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := xmlLocalName(t.Name)
+			path := fmt.Sprintf("%s/%s[%d]", top.path, name, top.children[name])
+			top.children[name]++
+			nodes = append(nodes, canonicalNode{path: path})
+			attrs := append([]xml.Attr{}, t.Attr...)
+			sort.Slice(attrs, func(i, j int) bool {
+				return xmlLocalName(attrs[i].Name) < xmlLocalName(attrs[j].Name)
+			})
+			for _, attr := range attrs {
+				nodes = append(nodes, canonicalNode{path: path + "/@" + xmlLocalName(attr.Name), value: attr.Value})
+			}
+			stack = append(stack, &frame{path: path, children: map[string]int{}})
+		case xml.EndElement:
+			if text := strings.TrimSpace(top.text.String()); text != "" {
+				nodes = append(nodes, canonicalNode{path: top.path + "/#text", value: text})
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			top.text.Write(t)
+		}
+	}
+	return nodes, nil
+}
+
+func xmlLocalName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// nopWriteCloser adds a Close method that does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// teeWriteCloser writes to several writers but only closes one of them.
+type teeWriteCloser struct {
+	io.Writer
+	c io.Closer
+}
+
+func (t teeWriteCloser) Close() error {
+	return t.c.Close()
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package wxconv
+
+import (
+	"bytes"
+	"github.com/mdhender/wxconv/models/wxx"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "features-labels.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func() *wxx.Map {
+		m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	for _, tc := range []struct {
+		name   string
+		change func(m *wxx.Map)
+		want   []Difference
+	}{
+		{name: "same", change: func(m *wxx.Map) {}},
+		{name: "meta-data", change: func(m *wxx.Map) { m.MetaData.Created = "2000-01-01T00:00:00Z" }},
+		{name: "tile terrain",
+			change: func(m *wxx.Map) { m.TileAt(1, 0).Terrain = 3 },
+			want:   []Difference{{Path: "Tiles.TileRows[1][0].Terrain", A: "2", B: "3"}}},
+		{name: "label text",
+			change: func(m *wxx.Map) { m.Features[0].Label.InnerText = "Citadel" },
+			want:   []Difference{{Path: "Features[0].Label.InnerText", A: `"Capital"`, B: `"Citadel"`}}},
+		{name: "missing label",
+			change: func(m *wxx.Map) { m.Features[1].Label = nil },
+			want:   []Difference{{Path: "Features[1].Label", A: "*wxx.Label{...}", B: "nil"}}},
+		{name: "missing feature",
+			change: func(m *wxx.Map) { m.Features = m.Features[:1] },
+			want:   []Difference{{Path: "Features.length", A: "2", B: "1"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := load(), load()
+			tc.change(b)
+			if got := Diff(a, b); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if got := Diff(nil, nil); got != nil {
+		t.Errorf("nil, nil: got %v, want nil", got)
+	}
+	if got := Diff(load(), nil); len(got) != 1 || got[0].Path != "map" {
+		t.Errorf("map, nil: got %v, want a difference for the map", got)
+	}
+}
+
+func TestDiffXML(t *testing.T) {
+	const doc = `<map version="1.73" type="WORLD">
+<tiles tilesWide="2" tilesHigh="2"/>
+<labels><label style="Ocean">Sea of Stars</label></labels>
+<notes/>
+</map>`
+	for _, tc := range []struct {
+		name string
+		doc  string
+		want []Difference
+	}{
+		{name: "same", doc: doc},
+		{name: "formatting",
+			doc: `<map type="WORLD"  version="1.73"><!-- comment --><tiles tilesHigh="2" tilesWide="2"></tiles>
+<labels>
+  <label style="Ocean">  Sea of Stars  </label>
+</labels><notes></notes></map>`},
+		{name: "attribute",
+			doc:  strings.Replace(doc, `tilesWide="2"`, `tilesWide="3"`, 1),
+			want: []Difference{{Path: "/map[0]/tiles[0]/@tilesWide", A: "2", B: "3"}}},
+		{name: "text",
+			doc:  strings.Replace(doc, "Sea of Stars", "Sea of Storms", 1),
+			want: []Difference{{Path: "/map[0]/labels[0]/label[0]/#text", A: "Sea of Stars", B: "Sea of Storms"}}},
+		{name: "order",
+			doc:  strings.Replace(strings.Replace(doc, "<notes/>", "", 1), "<tiles ", "<notes/><tiles ", 1),
+			want: []Difference{{Path: "/map[0]/tiles[0]", A: "/map[0]/tiles[0]", B: "/map[0]/notes[0]"}}},
+		{name: "extra element",
+			doc:  strings.Replace(doc, "<notes/>", "<notes><note>Here</note></notes>", 1),
+			want: []Difference{{Path: "nodes", A: "11", B: "13"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DiffXML([]byte(doc), []byte(tc.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := DiffXML([]byte(doc), []byte("<map>")); err == nil {
+		t.Errorf("invalid document: got nil, want error")
+	}
+}
+
+// TestVerify checks that every test map survives a round trip, and that
+// a value the exporter writes differently is reported.
+func TestVerify(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "maps", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		src, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := Verify(bytes.NewReader(encodeWXX(t, src)), true, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if filepath.Base(input) == "v200.xml" {
+			// v2.x maps are written as v1.73
+			want := &VerifyReport{
+				Differences:    []Difference{{Path: "Version", A: `"2.00"`, B: `"1.73"`}},
+				XMLDifferences: []Difference{{Path: "/map[0]/@version", A: "2.00", B: "1.73"}},
+			}
+			if !reflect.DeepEqual(report.Differences, want.Differences) || !reflect.DeepEqual(report.XMLDifferences, want.XMLDifferences) {
+				t.Errorf("%s: got %v and %v, want %v and %v", input, report.Differences, report.XMLDifferences, want.Differences, want.XMLDifferences)
+			}
+			continue
+		}
+		if !report.OK() {
+			t.Errorf("%s: got %v and %v, want no differences", input, report.Differences, report.XMLDifferences)
+		}
+	}
+
+	// the exporter writes "46.180" as "46.18"
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "basic.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = bytes.Replace(src, []byte(`hexWidth="46.18"`), []byte(`hexWidth="46.180"`), 1)
+	report, err := Verify(bytes.NewReader(encodeWXX(t, src)), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Difference{{Path: "/map[0]/@hexWidth", A: "46.180", B: "46.18"}}
+	if len(report.Differences) != 0 {
+		t.Errorf("map: got %v, want no differences", report.Differences)
+	}
+	if !reflect.DeepEqual(report.XMLDifferences, want) {
+		t.Errorf("xml: got %v, want %v", report.XMLDifferences, want)
+	}
+	if report.OK() {
+		t.Errorf("OK: got true, want false")
+	}
+	// without -xml, the maps are the same
+	report, err = Verify(bytes.NewReader(encodeWXX(t, src)), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !report.OK() {
+		t.Errorf("OK without xml: got false, want true")
+	}
+
+	if report := (&VerifyReport{Differences: []Difference{{Path: "Tiles.TilesWide", A: "2", B: "3"}}}); report.OK() {
+		t.Errorf("OK with map differences: got true, want false")
+	}
+}
+
+// TestVerifyUnknownElements checks that elements we don't model are
+// written back in their original place and with their original prefix.
+func TestVerifyUnknownElements(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "maps", "unknown-elements.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Verify(bytes.NewReader(encodeWXX(t, src)), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range report.Differences {
+		t.Errorf("map: %s", diff)
+	}
+	for _, diff := range report.XMLDifferences {
+		t.Errorf("xml: %s", diff)
+	}
+
+	var out bytes.Buffer
+	m, err := Decode(bytes.NewReader(encodeWXX(t, src)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Encode(&out, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	utf8 := decodeWXX(t, out.Bytes())
+	for _, want := range []string{
+		`xmlns:wg="https://example.com/wxconv/test" wg:edition="2"`,
+		`<wg:layergroup name="Overlays" wg:collapsed="true">`,
+		`</feature><wg:marker ref="f1">`,
+	} {
+		if !bytes.Contains(utf8, []byte(want)) {
+			t.Errorf("output is missing %s", want)
+		}
+	}
+}